@@ -0,0 +1,13 @@
+CREATE TABLE "import_checkpoints" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"username" varchar(256) NOT NULL,
+	"year" integer NOT NULL,
+	"windowEndUnix" bigint NOT NULL,
+	"lastCompletedPage" integer DEFAULT 0 NOT NULL,
+	"totalPages" integer,
+	"lastScrobbledAtUnix" varchar(32),
+	"completed" boolean DEFAULT false NOT NULL,
+	CONSTRAINT "import_checkpoints_username_year_unique" UNIQUE("username","year")
+);
+--> statement-breakpoint
+ALTER TABLE "import_checkpoints" ADD CONSTRAINT "import_checkpoints_username_users_username_fk" FOREIGN KEY ("username") REFERENCES "public"."users"("username") ON DELETE no action ON UPDATE no action;
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- Remove duplicate scrobbles left behind by repeated imports before enforcing the natural key
+DELETE FROM "scrobbles" a
+USING "scrobbles" b
+WHERE a."username" = b."username"
+  AND a."scrobbledAtUnix" = b."scrobbledAtUnix"
+  AND a."artistName" = b."artistName"
+  AND a."trackName" = b."trackName"
+  AND a."id" > b."id";--> statement-breakpoint
+ALTER TABLE "scrobbles" ADD CONSTRAINT "scrobbles_natural_key_unique" UNIQUE("username","scrobbledAtUnix","artistName","trackName");
\ No newline at end of file
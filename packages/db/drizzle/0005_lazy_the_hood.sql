@@ -0,0 +1,18 @@
+CREATE TABLE "fetch_jobs" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"kind" varchar(32) NOT NULL,
+	"username" varchar(256) NOT NULL,
+	"year" integer NOT NULL,
+	"status" varchar(32) DEFAULT 'pending' NOT NULL,
+	"progress" integer DEFAULT 0 NOT NULL,
+	"totalPages" integer,
+	"errorMessage" text,
+	"createdAt" timestamp with time zone DEFAULT now() NOT NULL,
+	"updatedAt" timestamp with time zone DEFAULT now() NOT NULL,
+	"startedAt" timestamp with time zone,
+	"finishedAt" timestamp with time zone
+);
+--> statement-breakpoint
+ALTER TABLE "fetch_jobs" ADD CONSTRAINT "fetch_jobs_username_users_username_fk" FOREIGN KEY ("username") REFERENCES "public"."users"("username") ON DELETE no action ON UPDATE no action;--> statement-breakpoint
+CREATE INDEX "fetch_jobs_status_created_at_idx" ON "fetch_jobs" USING btree ("status","createdAt");--> statement-breakpoint
+CREATE INDEX "fetch_jobs_username_year_idx" ON "fetch_jobs" USING btree ("username","year");
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE "fetch_jobs" ADD COLUMN "phase" varchar(32);--> statement-breakpoint
+ALTER TABLE "fetch_jobs" ADD COLUMN "totalScrobbles" integer;--> statement-breakpoint
+ALTER TABLE "fetch_jobs" ADD COLUMN "scrobblesInserted" integer DEFAULT 0 NOT NULL;--> statement-breakpoint
+ALTER TABLE "fetch_jobs" ADD COLUMN "scrobblesAlreadyPresent" integer DEFAULT 0 NOT NULL;--> statement-breakpoint
+ALTER TABLE "fetch_jobs" ADD COLUMN "mbidFound" integer DEFAULT 0 NOT NULL;--> statement-breakpoint
+ALTER TABLE "fetch_jobs" ADD COLUMN "fuzzyFound" integer DEFAULT 0 NOT NULL;--> statement-breakpoint
+ALTER TABLE "fetch_jobs" ADD COLUMN "notFound" integer DEFAULT 0 NOT NULL;
\ No newline at end of file
@@ -0,0 +1,19 @@
+CREATE TABLE "album_year_cache" (
+	"albumMbid" varchar(36) PRIMARY KEY NOT NULL,
+	"releaseYear" integer,
+	"lookedUpAt" timestamp with time zone DEFAULT now() NOT NULL
+);
+--> statement-breakpoint
+CREATE TABLE "recording_year_cache" (
+	"trackMbid" varchar(36) PRIMARY KEY NOT NULL,
+	"releaseYear" integer,
+	"lookedUpAt" timestamp with time zone DEFAULT now() NOT NULL
+);
+--> statement-breakpoint
+CREATE TABLE "artist_track_year_cache" (
+	"artistKey" text NOT NULL,
+	"trackKey" text NOT NULL,
+	"releaseYear" integer,
+	"lookedUpAt" timestamp with time zone DEFAULT now() NOT NULL,
+	CONSTRAINT "artist_track_year_cache_artistKey_trackKey_pk" PRIMARY KEY("artistKey","trackKey")
+);
\ No newline at end of file
@@ -0,0 +1,10 @@
+ALTER TABLE "album_year_cache" ADD COLUMN "releaseGroupMbid" varchar(36);--> statement-breakpoint
+ALTER TABLE "artist_track_year_cache" ADD COLUMN "method" varchar(32);--> statement-breakpoint
+ALTER TABLE "artist_track_year_cache" ADD COLUMN "releaseGroupMbid" varchar(36);--> statement-breakpoint
+ALTER TABLE "artist_track_year_cache" ADD COLUMN "recordingMbid" varchar(36);--> statement-breakpoint
+ALTER TABLE "artist_track_year_cache" ADD COLUMN "confidence" real;--> statement-breakpoint
+ALTER TABLE "recording_year_cache" ADD COLUMN "releaseGroupMbid" varchar(36);--> statement-breakpoint
+ALTER TABLE "scrobbles" ADD COLUMN "releaseYearMethod" varchar(32);--> statement-breakpoint
+ALTER TABLE "scrobbles" ADD COLUMN "matchedReleaseGroupMbid" varchar(36);--> statement-breakpoint
+ALTER TABLE "scrobbles" ADD COLUMN "matchedRecordingMbid" varchar(36);--> statement-breakpoint
+ALTER TABLE "scrobbles" ADD COLUMN "releaseYearConfidence" real;
\ No newline at end of file
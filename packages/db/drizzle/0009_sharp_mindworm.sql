@@ -0,0 +1,3 @@
+ALTER TABLE "album_year_cache" ADD COLUMN "rules" varchar(128);--> statement-breakpoint
+ALTER TABLE "artist_track_year_cache" ADD COLUMN "rules" varchar(128);--> statement-breakpoint
+ALTER TABLE "recording_year_cache" ADD COLUMN "rules" varchar(128);
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE "artist_album_year_cache" (
+	"artistKey" text NOT NULL,
+	"albumKey" text NOT NULL,
+	"releaseYear" integer,
+	"releaseGroupMbid" varchar(36),
+	"confidence" real,
+	"rules" varchar(128),
+	"lookedUpAt" timestamp with time zone DEFAULT now() NOT NULL,
+	CONSTRAINT "artist_album_year_cache_artistKey_albumKey_pk" PRIMARY KEY("artistKey","albumKey")
+);
\ No newline at end of file
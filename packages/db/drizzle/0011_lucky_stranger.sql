@@ -0,0 +1,3 @@
+ALTER TABLE "album_year_cache" ADD COLUMN "redirected" boolean DEFAULT false NOT NULL;--> statement-breakpoint
+ALTER TABLE "fetch_jobs" ADD COLUMN "mbidRedirected" integer DEFAULT 0 NOT NULL;--> statement-breakpoint
+ALTER TABLE "recording_year_cache" ADD COLUMN "redirected" boolean DEFAULT false NOT NULL;
\ No newline at end of file
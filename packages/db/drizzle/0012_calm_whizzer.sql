@@ -0,0 +1,10 @@
+ALTER TABLE "album_year_cache" ADD COLUMN "releaseMonth" integer;--> statement-breakpoint
+ALTER TABLE "album_year_cache" ADD COLUMN "releaseDay" integer;--> statement-breakpoint
+ALTER TABLE "artist_album_year_cache" ADD COLUMN "releaseMonth" integer;--> statement-breakpoint
+ALTER TABLE "artist_album_year_cache" ADD COLUMN "releaseDay" integer;--> statement-breakpoint
+ALTER TABLE "artist_track_year_cache" ADD COLUMN "releaseMonth" integer;--> statement-breakpoint
+ALTER TABLE "artist_track_year_cache" ADD COLUMN "releaseDay" integer;--> statement-breakpoint
+ALTER TABLE "recording_year_cache" ADD COLUMN "releaseMonth" integer;--> statement-breakpoint
+ALTER TABLE "recording_year_cache" ADD COLUMN "releaseDay" integer;--> statement-breakpoint
+ALTER TABLE "scrobbles" ADD COLUMN "releaseMonth" integer;--> statement-breakpoint
+ALTER TABLE "scrobbles" ADD COLUMN "releaseDay" integer;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE "genre_cache" (
+	"genreKey" text PRIMARY KEY NOT NULL,
+	"genres" text[] NOT NULL,
+	"rules" varchar(128),
+	"lookedUpAt" timestamp with time zone DEFAULT now() NOT NULL
+);
+--> statement-breakpoint
+ALTER TABLE "fetch_jobs" ADD COLUMN "genresFound" integer DEFAULT 0 NOT NULL;--> statement-breakpoint
+ALTER TABLE "fetch_jobs" ADD COLUMN "genresNotFound" integer DEFAULT 0 NOT NULL;--> statement-breakpoint
+ALTER TABLE "scrobbles" ADD COLUMN "genres" text[];--> statement-breakpoint
+ALTER TABLE "scrobbles" ADD COLUMN "genresFetched" boolean DEFAULT false NOT NULL;
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- Keep only the newest active job per kind, user and year before enforcing it
+UPDATE "fetch_jobs" SET "status" = 'cancelled', "finishedAt" = now(), "updatedAt" = now()
+WHERE "status" IN ('pending', 'fetching', 'augmenting')
+  AND "id" NOT IN (
+    SELECT DISTINCT ON ("kind", "username", "year") "id"
+    FROM "fetch_jobs"
+    WHERE "status" IN ('pending', 'fetching', 'augmenting')
+    ORDER BY "kind", "username", "year", "createdAt" DESC
+  );--> statement-breakpoint
+CREATE UNIQUE INDEX "fetch_jobs_active_unique" ON "fetch_jobs" USING btree ("kind","username","year") WHERE "fetch_jobs"."status" in ('pending', 'fetching', 'augmenting');
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- Scrobbles resolved before release dates carried a month and day keep their year but are
+-- looked up again, so the next release year run fills in releaseMonth and releaseDay
+UPDATE "scrobbles" SET "releaseYearFetched" = false
+WHERE "releaseYearFetched" = true AND "releaseYear" IS NOT NULL AND "releaseMonth" IS NULL;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE "fetch_jobs" ADD COLUMN "genresTimedOut" integer DEFAULT 0 NOT NULL;--> statement-breakpoint
+ALTER TABLE "fetch_jobs" ADD COLUMN "genresErrors" integer DEFAULT 0 NOT NULL;--> statement-breakpoint
+ALTER TABLE "fetch_jobs" ADD COLUMN "genresCacheHits" integer DEFAULT 0 NOT NULL;
\ No newline at end of file
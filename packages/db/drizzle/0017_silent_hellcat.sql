@@ -0,0 +1 @@
+ALTER TABLE "fetch_jobs" ADD COLUMN "genreErrorMessage" text;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE "fetch_jobs" ADD COLUMN "claimedBy" varchar(128);--> statement-breakpoint
+ALTER TABLE "fetch_jobs" ADD COLUMN "heartbeatAt" timestamp with time zone;
\ No newline at end of file
@@ -43,7 +43,7 @@ async function flush() {
   console.log("Flushing database...");
 
   // Truncate all tables with CASCADE to handle foreign keys
-  await db.execute(sql`TRUNCATE TABLE scrobbles, users CASCADE`);
+  await db.execute(sql`TRUNCATE TABLE album_year_cache, artist_album_year_cache, artist_track_year_cache, fetch_jobs, genre_cache, import_checkpoints, recording_year_cache, scrobbles, users CASCADE`);
 
   console.log("All tables truncated.");
   await client.end();
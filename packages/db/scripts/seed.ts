@@ -123,7 +123,7 @@ async function seed() {
     };
   });
 
-  await db.insert(scrobbles).values(scrobbleValues);
+  await db.insert(scrobbles).values(scrobbleValues).onConflictDoNothing();
   console.log(`Inserted ${scrobbleValues.length} scrobbles.`);
 
   console.log("Seeding complete.");
@@ -1,11 +1,17 @@
 import { sql } from "drizzle-orm";
 import {
+  bigint,
   boolean,
   check,
   index,
   integer,
   pgTable,
+  primaryKey,
+  real,
+  text,
   timestamp,
+  unique,
+  uniqueIndex,
   uuid,
   varchar,
 } from "drizzle-orm/pg-core";
@@ -45,13 +51,32 @@ export const scrobbles = pgTable(
 
     // MusicBrainz release year lookup
     releaseYear: integer(), // Year of release from MusicBrainz (NULL if not found)
+    releaseMonth: integer(), // 1-12, NULL when MusicBrainz only knows the year
+    releaseDay: integer(), // 1-31, NULL when MusicBrainz doesn't know the day
     releaseYearFetched: boolean().default(false).notNull(), // Track whether MB lookup has been attempted
+
+    // Provenance of the release year match
+    releaseYearMethod: varchar({ length: 32 }), // album_mbid, track_mbid, fuzzy_album, fuzzy, fuzzy_first_artist, fuzzy_transliterated, not_found
+    matchedReleaseGroupMbid: varchar({ length: 36 }),
+    matchedRecordingMbid: varchar({ length: 36 }), // NULL when matched by album MBID
+    releaseYearConfidence: real(), // 0-1, NULL when not found
+
+    // MusicBrainz genres, best first, from the matched recording, release group and artists
+    genres: text().array(), // Empty when MusicBrainz has no genre tags for the match
+    genresFetched: boolean().default(false).notNull(), // Track whether the genre lookup has been attempted
   },
   (table) => [
     // Composite index for the main query pattern: user + year
     index("scrobbles_username_year_idx").on(table.username, table.year),
     // Index for date-based sorting within year
     index("scrobbles_scrobbled_at_idx").on(table.scrobbledAt),
+    // Natural key so re-importing a user/year never duplicates scrobbles
+    unique("scrobbles_natural_key_unique").on(
+      table.username,
+      table.scrobbledAtUnix,
+      table.artistName,
+      table.trackName,
+    ),
 
     // Validate MBIDs are either NULL or valid UUID format (36 chars, proper format)
     check(
@@ -68,3 +93,165 @@ export const scrobbles = pgTable(
     ),
   ],
 );
+
+export const importCheckpoints = pgTable(
+  "import_checkpoints",
+  {
+    id: uuid().defaultRandom().primaryKey(),
+
+    // User + year being imported
+    username: varchar({ length: 256 })
+      .notNull()
+      .references(() => users.username),
+    year: integer().notNull(),
+
+    // Upper bound of the Last.fm from/to window, pinned so page boundaries stay stable on resume
+    windowEndUnix: bigint({ mode: "number" }).notNull(),
+
+    // Progress through user.getrecenttracks
+    lastCompletedPage: integer().default(0).notNull(),
+    totalPages: integer(), // NULL until the first page has been fetched
+    lastScrobbledAtUnix: varchar({ length: 32 }), // Oldest UTS on the last completed page
+    completed: boolean().default(false).notNull(),
+  },
+  (table) => [
+    // One checkpoint per user + year
+    unique("import_checkpoints_username_year_unique").on(
+      table.username,
+      table.year,
+    ),
+  ],
+);
+
+export const fetchJobs = pgTable(
+  "fetch_jobs",
+  {
+    id: uuid().defaultRandom().primaryKey(),
+
+    // What to run and for whom
+    kind: varchar({ length: 32 }).notNull(), // import, find_release_years (which also looks up genres)
+    username: varchar({ length: 256 })
+      .notNull()
+      .references(() => users.username),
+    year: integer().notNull(),
+
+    // Lifecycle: pending -> fetching (import) / augmenting (find_release_years) -> completed | failed | cancelled
+    status: varchar({ length: 32 }).default("pending").notNull(),
+    phase: varchar({ length: 32 }), // Step within a running status: fetch, mbid, fuzzy, genres
+    progress: integer().default(0).notNull(), // Pages fetched (import) or scrobbles processed in the release year or genres phase
+    totalPages: integer(), // Import only, NULL until the first page has been fetched
+    totalScrobbles: integer(), // Tracks reported by Last.fm (import) or scrobbles to look up in the current phase
+    errorMessage: text(),
+    genreErrorMessage: text(), // Why the genre lookup failed; the release years are kept and the job still completes
+
+    // Import counters
+    scrobblesInserted: integer().default(0).notNull(),
+    scrobblesAlreadyPresent: integer().default(0).notNull(),
+
+    // Release year lookup counters
+    mbidFound: integer().default(0).notNull(),
+    mbidRedirected: integer().default(0).notNull(), // Part of mbidFound, resolved through a merged entity's old MBID
+    fuzzyFound: integer().default(0).notNull(),
+    notFound: integer().default(0).notNull(),
+
+    // Genre lookup counters
+    genresFound: integer().default(0).notNull(),
+    genresNotFound: integer().default(0).notNull(),
+    genresTimedOut: integer().default(0).notNull(), // Left for the next run
+    genresErrors: integer().default(0).notNull(), // Failed lookups, also left for the next run
+    genresCacheHits: integer().default(0).notNull(), // Lookups answered from genre_cache
+
+    // Timestamps are set explicitly by the worker's job queries
+    createdAt: timestamp({ withTimezone: true }).defaultNow().notNull(),
+    updatedAt: timestamp({ withTimezone: true }).defaultNow().notNull(),
+    startedAt: timestamp({ withTimezone: true }),
+    finishedAt: timestamp({ withTimezone: true }),
+
+    // Worker process running the job and its last sign of life; a running job whose heartbeat
+    // goes stale is requeued by any worker
+    claimedBy: varchar({ length: 128 }),
+    heartbeatAt: timestamp({ withTimezone: true }),
+  },
+  (table) => [
+    // Claiming the oldest pending job
+    index("fetch_jobs_status_created_at_idx").on(table.status, table.createdAt),
+    // Looking up jobs for a user + year
+    index("fetch_jobs_username_year_idx").on(table.username, table.year),
+    // At most one active job per kind, user and year, so concurrent requests can't queue duplicates
+    uniqueIndex("fetch_jobs_active_unique")
+      .on(table.kind, table.username, table.year)
+      .where(sql`${table.status} in ('pending', 'fetching', 'augmenting')`),
+  ],
+);
+
+// MusicBrainz lookup caches shared across users. Release years don't change, so a found year is
+// kept forever; a NULL releaseYear is a negative entry re-checked once it is older than the
+// worker's RELEASE_YEAR_NEGATIVE_CACHE_TTL. Entries only count when "rules" matches the worker's
+// current year resolution settings.
+export const albumYearCache = pgTable("album_year_cache", {
+  albumMbid: varchar({ length: 36 }).primaryKey(), // Lowercased Last.fm album MBID
+  releaseYear: integer(),
+  releaseMonth: integer(),
+  releaseDay: integer(),
+  releaseGroupMbid: varchar({ length: 36 }),
+  redirected: boolean().default(false).notNull(), // albumMbid is a merged release's old MBID
+  rules: varchar({ length: 128 }),
+  lookedUpAt: timestamp({ withTimezone: true }).defaultNow().notNull(),
+});
+
+export const recordingYearCache = pgTable("recording_year_cache", {
+  trackMbid: varchar({ length: 36 }).primaryKey(), // Lowercased Last.fm track MBID
+  releaseYear: integer(),
+  releaseMonth: integer(),
+  releaseDay: integer(),
+  releaseGroupMbid: varchar({ length: 36 }),
+  redirected: boolean().default(false).notNull(), // trackMbid is a merged recording's old MBID
+  rules: varchar({ length: 128 }),
+  lookedUpAt: timestamp({ withTimezone: true }).defaultNow().notNull(),
+});
+
+export const artistTrackYearCache = pgTable(
+  "artist_track_year_cache",
+  {
+    // Normalized names as used by the worker's fuzzy search
+    artistKey: text().notNull(),
+    trackKey: text().notNull(),
+    releaseYear: integer(),
+    releaseMonth: integer(),
+    releaseDay: integer(),
+    method: varchar({ length: 32 }), // fuzzy, fuzzy_first_artist or fuzzy_transliterated
+    releaseGroupMbid: varchar({ length: 36 }),
+    recordingMbid: varchar({ length: 36 }),
+    confidence: real(),
+    rules: varchar({ length: 128 }),
+    lookedUpAt: timestamp({ withTimezone: true }).defaultNow().notNull(),
+  },
+  (table) => [primaryKey({ columns: [table.artistKey, table.trackKey] })],
+);
+
+export const artistAlbumYearCache = pgTable(
+  "artist_album_year_cache",
+  {
+    // Normalized names as used by the worker's album-name search
+    artistKey: text().notNull(),
+    albumKey: text().notNull(),
+    releaseYear: integer(),
+    releaseMonth: integer(),
+    releaseDay: integer(),
+    releaseGroupMbid: varchar({ length: 36 }),
+    confidence: real(),
+    rules: varchar({ length: 128 }),
+    lookedUpAt: timestamp({ withTimezone: true }).defaultNow().notNull(),
+  },
+  (table) => [primaryKey({ columns: [table.artistKey, table.albumKey] })],
+);
+
+// Genres per MusicBrainz match, keyed like the worker's genre lookup groups. Unlike release years,
+// tags keep getting votes, so every entry is looked up again once it is older than the worker's
+// GENRE_CACHE_TTL.
+export const genreCache = pgTable("genre_cache", {
+  genreKey: text().primaryKey(),
+  genres: text().array().notNull(), // Best first, empty when MusicBrainz has no genre tags
+  rules: varchar({ length: 128 }),
+  lookedUpAt: timestamp({ withTimezone: true }).defaultNow().notNull(),
+});
@@ -0,0 +1,562 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: fetch_jobs.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const cancelFetchJob = `-- name: CancelFetchJob :exec
+UPDATE fetch_jobs
+SET
+    status = 'cancelled',
+    "finishedAt" = now(),
+    "updatedAt" = now()
+WHERE id = $1
+`
+
+func (q *Queries) CancelFetchJob(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, cancelFetchJob, id)
+	return err
+}
+
+const cancelPendingFetchJob = `-- name: CancelPendingFetchJob :execrows
+UPDATE fetch_jobs
+SET
+    status = 'cancelled',
+    "finishedAt" = now(),
+    "updatedAt" = now()
+WHERE id = $1
+  AND status = 'pending'
+`
+
+func (q *Queries) CancelPendingFetchJob(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, cancelPendingFetchJob, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const claimNextFetchJob = `-- name: ClaimNextFetchJob :one
+UPDATE fetch_jobs
+SET
+    status = CASE WHEN kind = 'import' THEN 'fetching' ELSE 'augmenting' END,
+    "claimedBy" = $1,
+    "heartbeatAt" = now(),
+    "startedAt" = now(),
+    "updatedAt" = now()
+WHERE id = (
+    SELECT id
+    FROM fetch_jobs
+    WHERE status = 'pending'
+    ORDER BY "createdAt"
+    FOR UPDATE SKIP LOCKED
+    LIMIT 1
+)
+RETURNING id, kind, username, year
+`
+
+type ClaimNextFetchJobRow struct {
+	ID       pgtype.UUID `json:"id"`
+	Kind     string      `json:"kind"`
+	Username string      `json:"username"`
+	Year     int32       `json:"year"`
+}
+
+func (q *Queries) ClaimNextFetchJob(ctx context.Context, claimedBy pgtype.Text) (ClaimNextFetchJobRow, error) {
+	row := q.db.QueryRow(ctx, claimNextFetchJob, claimedBy)
+	var i ClaimNextFetchJobRow
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.Username,
+		&i.Year,
+	)
+	return i, err
+}
+
+const completeFetchJob = `-- name: CompleteFetchJob :exec
+UPDATE fetch_jobs
+SET
+    status = 'completed',
+    "finishedAt" = now(),
+    "updatedAt" = now()
+WHERE id = $1
+`
+
+func (q *Queries) CompleteFetchJob(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, completeFetchJob, id)
+	return err
+}
+
+const createFetchJob = `-- name: CreateFetchJob :one
+INSERT INTO fetch_jobs (kind, username, year)
+VALUES ($1, $2, $3)
+ON CONFLICT (kind, username, year) WHERE status IN ('pending', 'fetching', 'augmenting') DO NOTHING
+RETURNING id
+`
+
+type CreateFetchJobParams struct {
+	Kind     string `json:"kind"`
+	Username string `json:"username"`
+	Year     int32  `json:"year"`
+}
+
+// Returns no row when an identical job is already active (fetch_jobs_active_unique)
+func (q *Queries) CreateFetchJob(ctx context.Context, arg CreateFetchJobParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, createFetchJob, arg.Kind, arg.Username, arg.Year)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const failFetchJob = `-- name: FailFetchJob :exec
+UPDATE fetch_jobs
+SET
+    status = 'failed',
+    "errorMessage" = $2,
+    "finishedAt" = now(),
+    "updatedAt" = now()
+WHERE id = $1
+`
+
+type FailFetchJobParams struct {
+	ID           pgtype.UUID `json:"id"`
+	ErrorMessage pgtype.Text `json:"errorMessage"`
+}
+
+func (q *Queries) FailFetchJob(ctx context.Context, arg FailFetchJobParams) error {
+	_, err := q.db.Exec(ctx, failFetchJob, arg.ID, arg.ErrorMessage)
+	return err
+}
+
+const getActiveFetchJob = `-- name: GetActiveFetchJob :one
+SELECT id
+FROM fetch_jobs
+WHERE kind = $1
+  AND username = $2
+  AND year = $3
+  AND status IN ('pending', 'fetching', 'augmenting')
+ORDER BY "createdAt" DESC
+LIMIT 1
+`
+
+type GetActiveFetchJobParams struct {
+	Kind     string `json:"kind"`
+	Username string `json:"username"`
+	Year     int32  `json:"year"`
+}
+
+func (q *Queries) GetActiveFetchJob(ctx context.Context, arg GetActiveFetchJobParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, getActiveFetchJob, arg.Kind, arg.Username, arg.Year)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getFetchJob = `-- name: GetFetchJob :one
+SELECT
+    id,
+    kind,
+    username,
+    year,
+    status,
+    phase,
+    progress,
+    "totalPages",
+    "totalScrobbles",
+    "scrobblesInserted",
+    "scrobblesAlreadyPresent",
+    "mbidFound",
+    "mbidRedirected",
+    "fuzzyFound",
+    "notFound",
+    "genresFound",
+    "genresNotFound",
+    "genresTimedOut",
+    "genresErrors",
+    "genresCacheHits",
+    "errorMessage",
+    "genreErrorMessage",
+    "createdAt",
+    "updatedAt",
+    "startedAt",
+    "finishedAt"
+FROM fetch_jobs
+WHERE id = $1
+`
+
+type GetFetchJobRow struct {
+	ID                      pgtype.UUID        `json:"id"`
+	Kind                    string             `json:"kind"`
+	Username                string             `json:"username"`
+	Year                    int32              `json:"year"`
+	Status                  string             `json:"status"`
+	Phase                   pgtype.Text        `json:"phase"`
+	Progress                int32              `json:"progress"`
+	TotalPages              pgtype.Int4        `json:"totalPages"`
+	TotalScrobbles          pgtype.Int4        `json:"totalScrobbles"`
+	ScrobblesInserted       int32              `json:"scrobblesInserted"`
+	ScrobblesAlreadyPresent int32              `json:"scrobblesAlreadyPresent"`
+	MbidFound               int32              `json:"mbidFound"`
+	MbidRedirected          int32              `json:"mbidRedirected"`
+	FuzzyFound              int32              `json:"fuzzyFound"`
+	NotFound                int32              `json:"notFound"`
+	GenresFound             int32              `json:"genresFound"`
+	GenresNotFound          int32              `json:"genresNotFound"`
+	GenresTimedOut          int32              `json:"genresTimedOut"`
+	GenresErrors            int32              `json:"genresErrors"`
+	GenresCacheHits         int32              `json:"genresCacheHits"`
+	ErrorMessage            pgtype.Text        `json:"errorMessage"`
+	GenreErrorMessage       pgtype.Text        `json:"genreErrorMessage"`
+	CreatedAt               pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt               pgtype.Timestamptz `json:"updatedAt"`
+	StartedAt               pgtype.Timestamptz `json:"startedAt"`
+	FinishedAt              pgtype.Timestamptz `json:"finishedAt"`
+}
+
+func (q *Queries) GetFetchJob(ctx context.Context, id pgtype.UUID) (GetFetchJobRow, error) {
+	row := q.db.QueryRow(ctx, getFetchJob, id)
+	var i GetFetchJobRow
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.Username,
+		&i.Year,
+		&i.Status,
+		&i.Phase,
+		&i.Progress,
+		&i.TotalPages,
+		&i.TotalScrobbles,
+		&i.ScrobblesInserted,
+		&i.ScrobblesAlreadyPresent,
+		&i.MbidFound,
+		&i.MbidRedirected,
+		&i.FuzzyFound,
+		&i.NotFound,
+		&i.GenresFound,
+		&i.GenresNotFound,
+		&i.GenresTimedOut,
+		&i.GenresErrors,
+		&i.GenresCacheHits,
+		&i.ErrorMessage,
+		&i.GenreErrorMessage,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const listFetchJobs = `-- name: ListFetchJobs :many
+SELECT
+    id,
+    kind,
+    username,
+    year,
+    status,
+    phase,
+    progress,
+    "totalPages",
+    "totalScrobbles",
+    "scrobblesInserted",
+    "scrobblesAlreadyPresent",
+    "mbidFound",
+    "mbidRedirected",
+    "fuzzyFound",
+    "notFound",
+    "genresFound",
+    "genresNotFound",
+    "genresTimedOut",
+    "genresErrors",
+    "genresCacheHits",
+    "errorMessage",
+    "genreErrorMessage",
+    "createdAt",
+    "updatedAt",
+    "startedAt",
+    "finishedAt"
+FROM fetch_jobs
+WHERE ($1::varchar IS NULL OR username = $1)
+  AND ($2::integer IS NULL OR year = $2)
+ORDER BY "createdAt" DESC
+LIMIT $3
+`
+
+type ListFetchJobsParams struct {
+	Username pgtype.Text `json:"username"`
+	Year     pgtype.Int4 `json:"year"`
+	Limit    int32       `json:"limit"`
+}
+
+type ListFetchJobsRow struct {
+	ID                      pgtype.UUID        `json:"id"`
+	Kind                    string             `json:"kind"`
+	Username                string             `json:"username"`
+	Year                    int32              `json:"year"`
+	Status                  string             `json:"status"`
+	Phase                   pgtype.Text        `json:"phase"`
+	Progress                int32              `json:"progress"`
+	TotalPages              pgtype.Int4        `json:"totalPages"`
+	TotalScrobbles          pgtype.Int4        `json:"totalScrobbles"`
+	ScrobblesInserted       int32              `json:"scrobblesInserted"`
+	ScrobblesAlreadyPresent int32              `json:"scrobblesAlreadyPresent"`
+	MbidFound               int32              `json:"mbidFound"`
+	MbidRedirected          int32              `json:"mbidRedirected"`
+	FuzzyFound              int32              `json:"fuzzyFound"`
+	NotFound                int32              `json:"notFound"`
+	GenresFound             int32              `json:"genresFound"`
+	GenresNotFound          int32              `json:"genresNotFound"`
+	GenresTimedOut          int32              `json:"genresTimedOut"`
+	GenresErrors            int32              `json:"genresErrors"`
+	GenresCacheHits         int32              `json:"genresCacheHits"`
+	ErrorMessage            pgtype.Text        `json:"errorMessage"`
+	GenreErrorMessage       pgtype.Text        `json:"genreErrorMessage"`
+	CreatedAt               pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt               pgtype.Timestamptz `json:"updatedAt"`
+	StartedAt               pgtype.Timestamptz `json:"startedAt"`
+	FinishedAt              pgtype.Timestamptz `json:"finishedAt"`
+}
+
+func (q *Queries) ListFetchJobs(ctx context.Context, arg ListFetchJobsParams) ([]ListFetchJobsRow, error) {
+	rows, err := q.db.Query(ctx, listFetchJobs, arg.Username, arg.Year, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListFetchJobsRow{}
+	for rows.Next() {
+		var i ListFetchJobsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Username,
+			&i.Year,
+			&i.Status,
+			&i.Phase,
+			&i.Progress,
+			&i.TotalPages,
+			&i.TotalScrobbles,
+			&i.ScrobblesInserted,
+			&i.ScrobblesAlreadyPresent,
+			&i.MbidFound,
+			&i.MbidRedirected,
+			&i.FuzzyFound,
+			&i.NotFound,
+			&i.GenresFound,
+			&i.GenresNotFound,
+			&i.GenresTimedOut,
+			&i.GenresErrors,
+			&i.GenresCacheHits,
+			&i.ErrorMessage,
+			&i.GenreErrorMessage,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const releaseFetchJob = `-- name: ReleaseFetchJob :exec
+UPDATE fetch_jobs
+SET
+    status = 'pending',
+    "claimedBy" = NULL,
+    "heartbeatAt" = NULL,
+    "updatedAt" = now()
+WHERE id = $1
+  AND "claimedBy" = $2
+  AND status IN ('fetching', 'augmenting')
+`
+
+type ReleaseFetchJobParams struct {
+	ID        pgtype.UUID `json:"id"`
+	ClaimedBy pgtype.Text `json:"claimedBy"`
+}
+
+// Hands a job interrupted by shutdown back to the queue, unless another worker already took it over
+func (q *Queries) ReleaseFetchJob(ctx context.Context, arg ReleaseFetchJobParams) error {
+	_, err := q.db.Exec(ctx, releaseFetchJob, arg.ID, arg.ClaimedBy)
+	return err
+}
+
+const requeueStaleFetchJobs = `-- name: RequeueStaleFetchJobs :execrows
+UPDATE fetch_jobs
+SET
+    status = 'pending',
+    "claimedBy" = NULL,
+    "heartbeatAt" = NULL,
+    "updatedAt" = now()
+WHERE status IN ('fetching', 'augmenting')
+  AND ("heartbeatAt" IS NULL OR "heartbeatAt" < now() - $1::interval)
+`
+
+// Jobs claimed before heartbeats existed have none and count as stale
+func (q *Queries) RequeueStaleFetchJobs(ctx context.Context, staleAfter pgtype.Interval) (int64, error) {
+	result, err := q.db.Exec(ctx, requeueStaleFetchJobs, staleAfter)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setGenreJobError = `-- name: SetGenreJobError :exec
+UPDATE fetch_jobs
+SET
+    "genreErrorMessage" = $2,
+    "updatedAt" = now()
+WHERE id = $1
+`
+
+type SetGenreJobErrorParams struct {
+	ID                pgtype.UUID `json:"id"`
+	GenreErrorMessage pgtype.Text `json:"genreErrorMessage"`
+}
+
+func (q *Queries) SetGenreJobError(ctx context.Context, arg SetGenreJobErrorParams) error {
+	_, err := q.db.Exec(ctx, setGenreJobError, arg.ID, arg.GenreErrorMessage)
+	return err
+}
+
+const touchFetchJobHeartbeats = `-- name: TouchFetchJobHeartbeats :execrows
+UPDATE fetch_jobs
+SET "heartbeatAt" = now()
+WHERE "claimedBy" = $1
+  AND status IN ('fetching', 'augmenting')
+`
+
+func (q *Queries) TouchFetchJobHeartbeats(ctx context.Context, claimedby pgtype.Text) (int64, error) {
+	result, err := q.db.Exec(ctx, touchFetchJobHeartbeats, claimedby)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateGenreJobProgress = `-- name: UpdateGenreJobProgress :exec
+UPDATE fetch_jobs
+SET
+    phase = 'genres',
+    progress = $2,
+    "totalScrobbles" = $3,
+    "genresFound" = $4,
+    "genresNotFound" = $5,
+    "genresTimedOut" = $6,
+    "genresErrors" = $7,
+    "genresCacheHits" = $8,
+    "updatedAt" = now()
+WHERE id = $1
+`
+
+type UpdateGenreJobProgressParams struct {
+	ID              pgtype.UUID `json:"id"`
+	Progress        int32       `json:"progress"`
+	TotalScrobbles  pgtype.Int4 `json:"totalScrobbles"`
+	GenresFound     int32       `json:"genresFound"`
+	GenresNotFound  int32       `json:"genresNotFound"`
+	GenresTimedOut  int32       `json:"genresTimedOut"`
+	GenresErrors    int32       `json:"genresErrors"`
+	GenresCacheHits int32       `json:"genresCacheHits"`
+}
+
+func (q *Queries) UpdateGenreJobProgress(ctx context.Context, arg UpdateGenreJobProgressParams) error {
+	_, err := q.db.Exec(ctx, updateGenreJobProgress,
+		arg.ID,
+		arg.Progress,
+		arg.TotalScrobbles,
+		arg.GenresFound,
+		arg.GenresNotFound,
+		arg.GenresTimedOut,
+		arg.GenresErrors,
+		arg.GenresCacheHits,
+	)
+	return err
+}
+
+const updateImportJobProgress = `-- name: UpdateImportJobProgress :exec
+UPDATE fetch_jobs
+SET
+    phase = 'fetch',
+    progress = $2,
+    "totalPages" = $3,
+    "totalScrobbles" = $4,
+    "scrobblesInserted" = $5,
+    "scrobblesAlreadyPresent" = $6,
+    "updatedAt" = now()
+WHERE id = $1
+`
+
+type UpdateImportJobProgressParams struct {
+	ID                      pgtype.UUID `json:"id"`
+	Progress                int32       `json:"progress"`
+	TotalPages              pgtype.Int4 `json:"totalPages"`
+	TotalScrobbles          pgtype.Int4 `json:"totalScrobbles"`
+	ScrobblesInserted       int32       `json:"scrobblesInserted"`
+	ScrobblesAlreadyPresent int32       `json:"scrobblesAlreadyPresent"`
+}
+
+func (q *Queries) UpdateImportJobProgress(ctx context.Context, arg UpdateImportJobProgressParams) error {
+	_, err := q.db.Exec(ctx, updateImportJobProgress,
+		arg.ID,
+		arg.Progress,
+		arg.TotalPages,
+		arg.TotalScrobbles,
+		arg.ScrobblesInserted,
+		arg.ScrobblesAlreadyPresent,
+	)
+	return err
+}
+
+const updateReleaseYearJobProgress = `-- name: UpdateReleaseYearJobProgress :exec
+UPDATE fetch_jobs
+SET
+    phase = $2,
+    progress = $3,
+    "totalScrobbles" = $4,
+    "mbidFound" = $5,
+    "fuzzyFound" = $6,
+    "notFound" = $7,
+    "mbidRedirected" = $8,
+    "updatedAt" = now()
+WHERE id = $1
+`
+
+type UpdateReleaseYearJobProgressParams struct {
+	ID             pgtype.UUID `json:"id"`
+	Phase          pgtype.Text `json:"phase"`
+	Progress       int32       `json:"progress"`
+	TotalScrobbles pgtype.Int4 `json:"totalScrobbles"`
+	MbidFound      int32       `json:"mbidFound"`
+	FuzzyFound     int32       `json:"fuzzyFound"`
+	NotFound       int32       `json:"notFound"`
+	MbidRedirected int32       `json:"mbidRedirected"`
+}
+
+func (q *Queries) UpdateReleaseYearJobProgress(ctx context.Context, arg UpdateReleaseYearJobProgressParams) error {
+	_, err := q.db.Exec(ctx, updateReleaseYearJobProgress,
+		arg.ID,
+		arg.Phase,
+		arg.Progress,
+		arg.TotalScrobbles,
+		arg.MbidFound,
+		arg.FuzzyFound,
+		arg.NotFound,
+		arg.MbidRedirected,
+	)
+	return err
+}
@@ -0,0 +1,71 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: genre_cache.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const getGenreCache = `-- name: GetGenreCache :many
+SELECT "genreKey", genres
+FROM genre_cache
+WHERE "genreKey" = ANY($1::text[])
+  AND rules = $2
+  AND "lookedUpAt" > $3
+`
+
+type GetGenreCacheParams struct {
+	GenreKeys  []string           `json:"genre_keys"`
+	Rules      pgtype.Text        `json:"rules"`
+	FreshSince pgtype.Timestamptz `json:"fresh_since"`
+}
+
+type GetGenreCacheRow struct {
+	GenreKey string   `json:"genreKey"`
+	Genres   []string `json:"genres"`
+}
+
+func (q *Queries) GetGenreCache(ctx context.Context, arg GetGenreCacheParams) ([]GetGenreCacheRow, error) {
+	rows, err := q.db.Query(ctx, getGenreCache, arg.GenreKeys, arg.Rules, arg.FreshSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetGenreCacheRow{}
+	for rows.Next() {
+		var i GetGenreCacheRow
+		if err := rows.Scan(&i.GenreKey, &i.Genres); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertGenreCache = `-- name: UpsertGenreCache :exec
+INSERT INTO genre_cache ("genreKey", genres, rules, "lookedUpAt")
+VALUES ($1, $2, $3, now())
+ON CONFLICT ("genreKey") DO UPDATE SET
+    genres = EXCLUDED.genres,
+    rules = EXCLUDED.rules,
+    "lookedUpAt" = EXCLUDED."lookedUpAt"
+`
+
+type UpsertGenreCacheParams struct {
+	GenreKey string      `json:"genreKey"`
+	Genres   []string    `json:"genres"`
+	Rules    pgtype.Text `json:"rules"`
+}
+
+func (q *Queries) UpsertGenreCache(ctx context.Context, arg UpsertGenreCacheParams) error {
+	_, err := q.db.Exec(ctx, upsertGenreCache, arg.GenreKey, arg.Genres, arg.Rules)
+	return err
+}
@@ -0,0 +1,119 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: import_checkpoints.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const completeImportCheckpoint = `-- name: CompleteImportCheckpoint :exec
+UPDATE import_checkpoints
+SET completed = true
+WHERE username = $1
+  AND year = $2
+`
+
+type CompleteImportCheckpointParams struct {
+	Username string `json:"username"`
+	Year     int32  `json:"year"`
+}
+
+func (q *Queries) CompleteImportCheckpoint(ctx context.Context, arg CompleteImportCheckpointParams) error {
+	_, err := q.db.Exec(ctx, completeImportCheckpoint, arg.Username, arg.Year)
+	return err
+}
+
+const getImportCheckpoint = `-- name: GetImportCheckpoint :one
+SELECT
+    "windowEndUnix",
+    "lastCompletedPage",
+    "totalPages",
+    "lastScrobbledAtUnix",
+    completed
+FROM import_checkpoints
+WHERE username = $1
+  AND year = $2
+`
+
+type GetImportCheckpointParams struct {
+	Username string `json:"username"`
+	Year     int32  `json:"year"`
+}
+
+type GetImportCheckpointRow struct {
+	WindowEndUnix       int64       `json:"windowEndUnix"`
+	LastCompletedPage   int32       `json:"lastCompletedPage"`
+	TotalPages          pgtype.Int4 `json:"totalPages"`
+	LastScrobbledAtUnix pgtype.Text `json:"lastScrobbledAtUnix"`
+	Completed           bool        `json:"completed"`
+}
+
+func (q *Queries) GetImportCheckpoint(ctx context.Context, arg GetImportCheckpointParams) (GetImportCheckpointRow, error) {
+	row := q.db.QueryRow(ctx, getImportCheckpoint, arg.Username, arg.Year)
+	var i GetImportCheckpointRow
+	err := row.Scan(
+		&i.WindowEndUnix,
+		&i.LastCompletedPage,
+		&i.TotalPages,
+		&i.LastScrobbledAtUnix,
+		&i.Completed,
+	)
+	return i, err
+}
+
+const startImportCheckpoint = `-- name: StartImportCheckpoint :exec
+INSERT INTO import_checkpoints (username, year, "windowEndUnix")
+VALUES ($1, $2, $3)
+ON CONFLICT (username, year)
+DO UPDATE SET
+    "windowEndUnix" = EXCLUDED."windowEndUnix",
+    "lastCompletedPage" = 0,
+    "totalPages" = NULL,
+    "lastScrobbledAtUnix" = NULL,
+    completed = false
+`
+
+type StartImportCheckpointParams struct {
+	Username      string `json:"username"`
+	Year          int32  `json:"year"`
+	WindowEndUnix int64  `json:"windowEndUnix"`
+}
+
+func (q *Queries) StartImportCheckpoint(ctx context.Context, arg StartImportCheckpointParams) error {
+	_, err := q.db.Exec(ctx, startImportCheckpoint, arg.Username, arg.Year, arg.WindowEndUnix)
+	return err
+}
+
+const updateImportCheckpointProgress = `-- name: UpdateImportCheckpointProgress :exec
+UPDATE import_checkpoints
+SET
+    "lastCompletedPage" = $3,
+    "totalPages" = $4,
+    "lastScrobbledAtUnix" = $5
+WHERE username = $1
+  AND year = $2
+`
+
+type UpdateImportCheckpointProgressParams struct {
+	Username            string      `json:"username"`
+	Year                int32       `json:"year"`
+	LastCompletedPage   int32       `json:"lastCompletedPage"`
+	TotalPages          pgtype.Int4 `json:"totalPages"`
+	LastScrobbledAtUnix pgtype.Text `json:"lastScrobbledAtUnix"`
+}
+
+func (q *Queries) UpdateImportCheckpointProgress(ctx context.Context, arg UpdateImportCheckpointProgressParams) error {
+	_, err := q.db.Exec(ctx, updateImportCheckpointProgress,
+		arg.Username,
+		arg.Year,
+		arg.LastCompletedPage,
+		arg.TotalPages,
+		arg.LastScrobbledAtUnix,
+	)
+	return err
+}
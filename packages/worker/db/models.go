@@ -8,20 +8,138 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AlbumYearCache struct {
+	AlbumMbid        string             `json:"albumMbid"`
+	ReleaseYear      pgtype.Int4        `json:"releaseYear"`
+	LookedUpAt       pgtype.Timestamptz `json:"lookedUpAt"`
+	ReleaseGroupMbid pgtype.Text        `json:"releaseGroupMbid"`
+	Rules            pgtype.Text        `json:"rules"`
+	Redirected       bool               `json:"redirected"`
+	ReleaseMonth     pgtype.Int4        `json:"releaseMonth"`
+	ReleaseDay       pgtype.Int4        `json:"releaseDay"`
+}
+
+type ArtistAlbumYearCache struct {
+	ArtistKey        string             `json:"artistKey"`
+	AlbumKey         string             `json:"albumKey"`
+	ReleaseYear      pgtype.Int4        `json:"releaseYear"`
+	ReleaseGroupMbid pgtype.Text        `json:"releaseGroupMbid"`
+	Confidence       pgtype.Float4      `json:"confidence"`
+	Rules            pgtype.Text        `json:"rules"`
+	LookedUpAt       pgtype.Timestamptz `json:"lookedUpAt"`
+	ReleaseMonth     pgtype.Int4        `json:"releaseMonth"`
+	ReleaseDay       pgtype.Int4        `json:"releaseDay"`
+}
+
+type ArtistTrackYearCache struct {
+	ArtistKey        string             `json:"artistKey"`
+	TrackKey         string             `json:"trackKey"`
+	ReleaseYear      pgtype.Int4        `json:"releaseYear"`
+	LookedUpAt       pgtype.Timestamptz `json:"lookedUpAt"`
+	Method           pgtype.Text        `json:"method"`
+	ReleaseGroupMbid pgtype.Text        `json:"releaseGroupMbid"`
+	RecordingMbid    pgtype.Text        `json:"recordingMbid"`
+	Confidence       pgtype.Float4      `json:"confidence"`
+	Rules            pgtype.Text        `json:"rules"`
+	ReleaseMonth     pgtype.Int4        `json:"releaseMonth"`
+	ReleaseDay       pgtype.Int4        `json:"releaseDay"`
+}
+
+type FetchJob struct {
+	ID                      pgtype.UUID        `json:"id"`
+	Kind                    string             `json:"kind"`
+	Username                string             `json:"username"`
+	Year                    int32              `json:"year"`
+	Status                  string             `json:"status"`
+	Progress                int32              `json:"progress"`
+	TotalPages              pgtype.Int4        `json:"totalPages"`
+	ErrorMessage            pgtype.Text        `json:"errorMessage"`
+	CreatedAt               pgtype.Timestamptz `json:"createdAt"`
+	UpdatedAt               pgtype.Timestamptz `json:"updatedAt"`
+	StartedAt               pgtype.Timestamptz `json:"startedAt"`
+	FinishedAt              pgtype.Timestamptz `json:"finishedAt"`
+	Phase                   pgtype.Text        `json:"phase"`
+	TotalScrobbles          pgtype.Int4        `json:"totalScrobbles"`
+	ScrobblesInserted       int32              `json:"scrobblesInserted"`
+	ScrobblesAlreadyPresent int32              `json:"scrobblesAlreadyPresent"`
+	MbidFound               int32              `json:"mbidFound"`
+	FuzzyFound              int32              `json:"fuzzyFound"`
+	NotFound                int32              `json:"notFound"`
+	MbidRedirected          int32              `json:"mbidRedirected"`
+	GenresFound             int32              `json:"genresFound"`
+	GenresNotFound          int32              `json:"genresNotFound"`
+	GenresTimedOut          int32              `json:"genresTimedOut"`
+	GenresErrors            int32              `json:"genresErrors"`
+	GenresCacheHits         int32              `json:"genresCacheHits"`
+	GenreErrorMessage       pgtype.Text        `json:"genreErrorMessage"`
+	ClaimedBy               pgtype.Text        `json:"claimedBy"`
+	HeartbeatAt             pgtype.Timestamptz `json:"heartbeatAt"`
+}
+
+type GenreCache struct {
+	GenreKey   string             `json:"genreKey"`
+	Genres     []string           `json:"genres"`
+	Rules      pgtype.Text        `json:"rules"`
+	LookedUpAt pgtype.Timestamptz `json:"lookedUpAt"`
+}
+
+type ImportCheckpoint struct {
+	ID                  pgtype.UUID `json:"id"`
+	Username            string      `json:"username"`
+	Year                int32       `json:"year"`
+	WindowEndUnix       int64       `json:"windowEndUnix"`
+	LastCompletedPage   int32       `json:"lastCompletedPage"`
+	TotalPages          pgtype.Int4 `json:"totalPages"`
+	LastScrobbledAtUnix pgtype.Text `json:"lastScrobbledAtUnix"`
+	Completed           bool        `json:"completed"`
+}
+
+type RecordingYearCache struct {
+	TrackMbid        string             `json:"trackMbid"`
+	ReleaseYear      pgtype.Int4        `json:"releaseYear"`
+	LookedUpAt       pgtype.Timestamptz `json:"lookedUpAt"`
+	ReleaseGroupMbid pgtype.Text        `json:"releaseGroupMbid"`
+	Rules            pgtype.Text        `json:"rules"`
+	Redirected       bool               `json:"redirected"`
+	ReleaseMonth     pgtype.Int4        `json:"releaseMonth"`
+	ReleaseDay       pgtype.Int4        `json:"releaseDay"`
+}
+
 type Scrobble struct {
-	ID                 pgtype.UUID        `json:"id"`
-	Username           string             `json:"username"`
-	TrackName          string             `json:"trackName"`
-	TrackMbid          pgtype.Text        `json:"trackMbid"`
-	ArtistName         string             `json:"artistName"`
-	ArtistMbid         pgtype.Text        `json:"artistMbid"`
-	AlbumName          pgtype.Text        `json:"albumName"`
-	AlbumMbid          pgtype.Text        `json:"albumMbid"`
-	ScrobbledAt        pgtype.Timestamptz `json:"scrobbledAt"`
-	ScrobbledAtUnix    string             `json:"scrobbledAtUnix"`
-	Year               int32              `json:"year"`
-	ReleaseYear        pgtype.Int4        `json:"releaseYear"`
-	ReleaseYearFetched bool               `json:"releaseYearFetched"`
+	ID                      pgtype.UUID        `json:"id"`
+	Username                string             `json:"username"`
+	TrackName               string             `json:"trackName"`
+	TrackMbid               pgtype.Text        `json:"trackMbid"`
+	ArtistName              string             `json:"artistName"`
+	ArtistMbid              pgtype.Text        `json:"artistMbid"`
+	AlbumName               pgtype.Text        `json:"albumName"`
+	AlbumMbid               pgtype.Text        `json:"albumMbid"`
+	ScrobbledAt             pgtype.Timestamptz `json:"scrobbledAt"`
+	ScrobbledAtUnix         string             `json:"scrobbledAtUnix"`
+	Year                    int32              `json:"year"`
+	ReleaseYear             pgtype.Int4        `json:"releaseYear"`
+	ReleaseYearFetched      bool               `json:"releaseYearFetched"`
+	ReleaseYearMethod       pgtype.Text        `json:"releaseYearMethod"`
+	MatchedReleaseGroupMbid pgtype.Text        `json:"matchedReleaseGroupMbid"`
+	MatchedRecordingMbid    pgtype.Text        `json:"matchedRecordingMbid"`
+	ReleaseYearConfidence   pgtype.Float4      `json:"releaseYearConfidence"`
+	ReleaseMonth            pgtype.Int4        `json:"releaseMonth"`
+	ReleaseDay              pgtype.Int4        `json:"releaseDay"`
+	Genres                  []string           `json:"genres"`
+	GenresFetched           bool               `json:"genresFetched"`
+}
+
+type ScrobblesStaging struct {
+	Username        string             `json:"username"`
+	TrackName       string             `json:"trackName"`
+	TrackMbid       pgtype.Text        `json:"trackMbid"`
+	ArtistName      string             `json:"artistName"`
+	ArtistMbid      pgtype.Text        `json:"artistMbid"`
+	AlbumName       pgtype.Text        `json:"albumName"`
+	AlbumMbid       pgtype.Text        `json:"albumMbid"`
+	ScrobbledAt     pgtype.Timestamptz `json:"scrobbledAt"`
+	ScrobbledAtUnix string             `json:"scrobbledAtUnix"`
+	Year            int32              `json:"year"`
 }
 
 type User struct {
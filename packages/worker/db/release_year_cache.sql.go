@@ -0,0 +1,412 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: release_year_cache.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const getAlbumYearCache = `-- name: GetAlbumYearCache :many
+SELECT "albumMbid", "releaseYear", "releaseMonth", "releaseDay", "releaseGroupMbid", redirected
+FROM album_year_cache
+WHERE "albumMbid" = ANY($1::varchar[])
+  AND rules = $2
+  AND ("releaseYear" IS NOT NULL OR "lookedUpAt" > $3)
+`
+
+type GetAlbumYearCacheParams struct {
+	AlbumMbids    []string           `json:"album_mbids"`
+	Rules         pgtype.Text        `json:"rules"`
+	NegativeSince pgtype.Timestamptz `json:"negative_since"`
+}
+
+type GetAlbumYearCacheRow struct {
+	AlbumMbid        string      `json:"albumMbid"`
+	ReleaseYear      pgtype.Int4 `json:"releaseYear"`
+	ReleaseMonth     pgtype.Int4 `json:"releaseMonth"`
+	ReleaseDay       pgtype.Int4 `json:"releaseDay"`
+	ReleaseGroupMbid pgtype.Text `json:"releaseGroupMbid"`
+	Redirected       bool        `json:"redirected"`
+}
+
+func (q *Queries) GetAlbumYearCache(ctx context.Context, arg GetAlbumYearCacheParams) ([]GetAlbumYearCacheRow, error) {
+	rows, err := q.db.Query(ctx, getAlbumYearCache, arg.AlbumMbids, arg.Rules, arg.NegativeSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetAlbumYearCacheRow{}
+	for rows.Next() {
+		var i GetAlbumYearCacheRow
+		if err := rows.Scan(
+			&i.AlbumMbid,
+			&i.ReleaseYear,
+			&i.ReleaseMonth,
+			&i.ReleaseDay,
+			&i.ReleaseGroupMbid,
+			&i.Redirected,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getArtistAlbumYearCache = `-- name: GetArtistAlbumYearCache :many
+SELECT "artistKey", "albumKey", "releaseYear", "releaseMonth", "releaseDay", "releaseGroupMbid", confidence
+FROM artist_album_year_cache
+WHERE "artistKey" = ANY($1::text[])
+  AND "albumKey" = ANY($2::text[])
+  AND rules = $3
+  AND ("releaseYear" IS NOT NULL OR "lookedUpAt" > $4)
+`
+
+type GetArtistAlbumYearCacheParams struct {
+	ArtistKeys    []string           `json:"artist_keys"`
+	AlbumKeys     []string           `json:"album_keys"`
+	Rules         pgtype.Text        `json:"rules"`
+	NegativeSince pgtype.Timestamptz `json:"negative_since"`
+}
+
+type GetArtistAlbumYearCacheRow struct {
+	ArtistKey        string        `json:"artistKey"`
+	AlbumKey         string        `json:"albumKey"`
+	ReleaseYear      pgtype.Int4   `json:"releaseYear"`
+	ReleaseMonth     pgtype.Int4   `json:"releaseMonth"`
+	ReleaseDay       pgtype.Int4   `json:"releaseDay"`
+	ReleaseGroupMbid pgtype.Text   `json:"releaseGroupMbid"`
+	Confidence       pgtype.Float4 `json:"confidence"`
+}
+
+// Matches every artist/album combination of the two lists; callers keep only the pairs they asked for
+func (q *Queries) GetArtistAlbumYearCache(ctx context.Context, arg GetArtistAlbumYearCacheParams) ([]GetArtistAlbumYearCacheRow, error) {
+	rows, err := q.db.Query(ctx, getArtistAlbumYearCache,
+		arg.ArtistKeys,
+		arg.AlbumKeys,
+		arg.Rules,
+		arg.NegativeSince,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetArtistAlbumYearCacheRow{}
+	for rows.Next() {
+		var i GetArtistAlbumYearCacheRow
+		if err := rows.Scan(
+			&i.ArtistKey,
+			&i.AlbumKey,
+			&i.ReleaseYear,
+			&i.ReleaseMonth,
+			&i.ReleaseDay,
+			&i.ReleaseGroupMbid,
+			&i.Confidence,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getArtistTrackYearCache = `-- name: GetArtistTrackYearCache :many
+SELECT "artistKey", "trackKey", "releaseYear", "releaseMonth", "releaseDay", method, "releaseGroupMbid", "recordingMbid", confidence
+FROM artist_track_year_cache
+WHERE "artistKey" = ANY($1::text[])
+  AND "trackKey" = ANY($2::text[])
+  AND rules = $3
+  AND ("releaseYear" IS NOT NULL OR "lookedUpAt" > $4)
+`
+
+type GetArtistTrackYearCacheParams struct {
+	ArtistKeys    []string           `json:"artist_keys"`
+	TrackKeys     []string           `json:"track_keys"`
+	Rules         pgtype.Text        `json:"rules"`
+	NegativeSince pgtype.Timestamptz `json:"negative_since"`
+}
+
+type GetArtistTrackYearCacheRow struct {
+	ArtistKey        string        `json:"artistKey"`
+	TrackKey         string        `json:"trackKey"`
+	ReleaseYear      pgtype.Int4   `json:"releaseYear"`
+	ReleaseMonth     pgtype.Int4   `json:"releaseMonth"`
+	ReleaseDay       pgtype.Int4   `json:"releaseDay"`
+	Method           pgtype.Text   `json:"method"`
+	ReleaseGroupMbid pgtype.Text   `json:"releaseGroupMbid"`
+	RecordingMbid    pgtype.Text   `json:"recordingMbid"`
+	Confidence       pgtype.Float4 `json:"confidence"`
+}
+
+// Matches every artist/track combination of the two lists; callers keep only the pairs they asked for
+func (q *Queries) GetArtistTrackYearCache(ctx context.Context, arg GetArtistTrackYearCacheParams) ([]GetArtistTrackYearCacheRow, error) {
+	rows, err := q.db.Query(ctx, getArtistTrackYearCache,
+		arg.ArtistKeys,
+		arg.TrackKeys,
+		arg.Rules,
+		arg.NegativeSince,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetArtistTrackYearCacheRow{}
+	for rows.Next() {
+		var i GetArtistTrackYearCacheRow
+		if err := rows.Scan(
+			&i.ArtistKey,
+			&i.TrackKey,
+			&i.ReleaseYear,
+			&i.ReleaseMonth,
+			&i.ReleaseDay,
+			&i.Method,
+			&i.ReleaseGroupMbid,
+			&i.RecordingMbid,
+			&i.Confidence,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecordingYearCache = `-- name: GetRecordingYearCache :many
+SELECT "trackMbid", "releaseYear", "releaseMonth", "releaseDay", "releaseGroupMbid", redirected
+FROM recording_year_cache
+WHERE "trackMbid" = ANY($1::varchar[])
+  AND rules = $2
+  AND ("releaseYear" IS NOT NULL OR "lookedUpAt" > $3)
+`
+
+type GetRecordingYearCacheParams struct {
+	TrackMbids    []string           `json:"track_mbids"`
+	Rules         pgtype.Text        `json:"rules"`
+	NegativeSince pgtype.Timestamptz `json:"negative_since"`
+}
+
+type GetRecordingYearCacheRow struct {
+	TrackMbid        string      `json:"trackMbid"`
+	ReleaseYear      pgtype.Int4 `json:"releaseYear"`
+	ReleaseMonth     pgtype.Int4 `json:"releaseMonth"`
+	ReleaseDay       pgtype.Int4 `json:"releaseDay"`
+	ReleaseGroupMbid pgtype.Text `json:"releaseGroupMbid"`
+	Redirected       bool        `json:"redirected"`
+}
+
+func (q *Queries) GetRecordingYearCache(ctx context.Context, arg GetRecordingYearCacheParams) ([]GetRecordingYearCacheRow, error) {
+	rows, err := q.db.Query(ctx, getRecordingYearCache, arg.TrackMbids, arg.Rules, arg.NegativeSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetRecordingYearCacheRow{}
+	for rows.Next() {
+		var i GetRecordingYearCacheRow
+		if err := rows.Scan(
+			&i.TrackMbid,
+			&i.ReleaseYear,
+			&i.ReleaseMonth,
+			&i.ReleaseDay,
+			&i.ReleaseGroupMbid,
+			&i.Redirected,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertAlbumYearCache = `-- name: UpsertAlbumYearCache :exec
+INSERT INTO album_year_cache ("albumMbid", "releaseYear", "releaseMonth", "releaseDay", "releaseGroupMbid", redirected, rules, "lookedUpAt")
+VALUES ($1, $2, $3, $4, $5, $6, $7, now())
+ON CONFLICT ("albumMbid") DO UPDATE SET
+    "releaseYear" = EXCLUDED."releaseYear",
+    "releaseMonth" = EXCLUDED."releaseMonth",
+    "releaseDay" = EXCLUDED."releaseDay",
+    "releaseGroupMbid" = EXCLUDED."releaseGroupMbid",
+    redirected = EXCLUDED.redirected,
+    rules = EXCLUDED.rules,
+    "lookedUpAt" = EXCLUDED."lookedUpAt"
+`
+
+type UpsertAlbumYearCacheParams struct {
+	AlbumMbid        string      `json:"albumMbid"`
+	ReleaseYear      pgtype.Int4 `json:"releaseYear"`
+	ReleaseMonth     pgtype.Int4 `json:"releaseMonth"`
+	ReleaseDay       pgtype.Int4 `json:"releaseDay"`
+	ReleaseGroupMbid pgtype.Text `json:"releaseGroupMbid"`
+	Redirected       bool        `json:"redirected"`
+	Rules            pgtype.Text `json:"rules"`
+}
+
+func (q *Queries) UpsertAlbumYearCache(ctx context.Context, arg UpsertAlbumYearCacheParams) error {
+	_, err := q.db.Exec(ctx, upsertAlbumYearCache,
+		arg.AlbumMbid,
+		arg.ReleaseYear,
+		arg.ReleaseMonth,
+		arg.ReleaseDay,
+		arg.ReleaseGroupMbid,
+		arg.Redirected,
+		arg.Rules,
+	)
+	return err
+}
+
+const upsertArtistAlbumYearCache = `-- name: UpsertArtistAlbumYearCache :exec
+INSERT INTO artist_album_year_cache (
+    "artistKey",
+    "albumKey",
+    "releaseYear",
+    "releaseMonth",
+    "releaseDay",
+    "releaseGroupMbid",
+    confidence,
+    rules,
+    "lookedUpAt"
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, now())
+ON CONFLICT ("artistKey", "albumKey") DO UPDATE SET
+    "releaseYear" = EXCLUDED."releaseYear",
+    "releaseMonth" = EXCLUDED."releaseMonth",
+    "releaseDay" = EXCLUDED."releaseDay",
+    "releaseGroupMbid" = EXCLUDED."releaseGroupMbid",
+    confidence = EXCLUDED.confidence,
+    rules = EXCLUDED.rules,
+    "lookedUpAt" = EXCLUDED."lookedUpAt"
+`
+
+type UpsertArtistAlbumYearCacheParams struct {
+	ArtistKey        string        `json:"artistKey"`
+	AlbumKey         string        `json:"albumKey"`
+	ReleaseYear      pgtype.Int4   `json:"releaseYear"`
+	ReleaseMonth     pgtype.Int4   `json:"releaseMonth"`
+	ReleaseDay       pgtype.Int4   `json:"releaseDay"`
+	ReleaseGroupMbid pgtype.Text   `json:"releaseGroupMbid"`
+	Confidence       pgtype.Float4 `json:"confidence"`
+	Rules            pgtype.Text   `json:"rules"`
+}
+
+func (q *Queries) UpsertArtistAlbumYearCache(ctx context.Context, arg UpsertArtistAlbumYearCacheParams) error {
+	_, err := q.db.Exec(ctx, upsertArtistAlbumYearCache,
+		arg.ArtistKey,
+		arg.AlbumKey,
+		arg.ReleaseYear,
+		arg.ReleaseMonth,
+		arg.ReleaseDay,
+		arg.ReleaseGroupMbid,
+		arg.Confidence,
+		arg.Rules,
+	)
+	return err
+}
+
+const upsertArtistTrackYearCache = `-- name: UpsertArtistTrackYearCache :exec
+INSERT INTO artist_track_year_cache (
+    "artistKey",
+    "trackKey",
+    "releaseYear",
+    "releaseMonth",
+    "releaseDay",
+    method,
+    "releaseGroupMbid",
+    "recordingMbid",
+    confidence,
+    rules,
+    "lookedUpAt"
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, now())
+ON CONFLICT ("artistKey", "trackKey") DO UPDATE SET
+    "releaseYear" = EXCLUDED."releaseYear",
+    "releaseMonth" = EXCLUDED."releaseMonth",
+    "releaseDay" = EXCLUDED."releaseDay",
+    method = EXCLUDED.method,
+    "releaseGroupMbid" = EXCLUDED."releaseGroupMbid",
+    "recordingMbid" = EXCLUDED."recordingMbid",
+    confidence = EXCLUDED.confidence,
+    rules = EXCLUDED.rules,
+    "lookedUpAt" = EXCLUDED."lookedUpAt"
+`
+
+type UpsertArtistTrackYearCacheParams struct {
+	ArtistKey        string        `json:"artistKey"`
+	TrackKey         string        `json:"trackKey"`
+	ReleaseYear      pgtype.Int4   `json:"releaseYear"`
+	ReleaseMonth     pgtype.Int4   `json:"releaseMonth"`
+	ReleaseDay       pgtype.Int4   `json:"releaseDay"`
+	Method           pgtype.Text   `json:"method"`
+	ReleaseGroupMbid pgtype.Text   `json:"releaseGroupMbid"`
+	RecordingMbid    pgtype.Text   `json:"recordingMbid"`
+	Confidence       pgtype.Float4 `json:"confidence"`
+	Rules            pgtype.Text   `json:"rules"`
+}
+
+func (q *Queries) UpsertArtistTrackYearCache(ctx context.Context, arg UpsertArtistTrackYearCacheParams) error {
+	_, err := q.db.Exec(ctx, upsertArtistTrackYearCache,
+		arg.ArtistKey,
+		arg.TrackKey,
+		arg.ReleaseYear,
+		arg.ReleaseMonth,
+		arg.ReleaseDay,
+		arg.Method,
+		arg.ReleaseGroupMbid,
+		arg.RecordingMbid,
+		arg.Confidence,
+		arg.Rules,
+	)
+	return err
+}
+
+const upsertRecordingYearCache = `-- name: UpsertRecordingYearCache :exec
+INSERT INTO recording_year_cache ("trackMbid", "releaseYear", "releaseMonth", "releaseDay", "releaseGroupMbid", redirected, rules, "lookedUpAt")
+VALUES ($1, $2, $3, $4, $5, $6, $7, now())
+ON CONFLICT ("trackMbid") DO UPDATE SET
+    "releaseYear" = EXCLUDED."releaseYear",
+    "releaseMonth" = EXCLUDED."releaseMonth",
+    "releaseDay" = EXCLUDED."releaseDay",
+    "releaseGroupMbid" = EXCLUDED."releaseGroupMbid",
+    redirected = EXCLUDED.redirected,
+    rules = EXCLUDED.rules,
+    "lookedUpAt" = EXCLUDED."lookedUpAt"
+`
+
+type UpsertRecordingYearCacheParams struct {
+	TrackMbid        string      `json:"trackMbid"`
+	ReleaseYear      pgtype.Int4 `json:"releaseYear"`
+	ReleaseMonth     pgtype.Int4 `json:"releaseMonth"`
+	ReleaseDay       pgtype.Int4 `json:"releaseDay"`
+	ReleaseGroupMbid pgtype.Text `json:"releaseGroupMbid"`
+	Redirected       bool        `json:"redirected"`
+	Rules            pgtype.Text `json:"rules"`
+}
+
+func (q *Queries) UpsertRecordingYearCache(ctx context.Context, arg UpsertRecordingYearCacheParams) error {
+	_, err := q.db.Exec(ctx, upsertRecordingYearCache,
+		arg.TrackMbid,
+		arg.ReleaseYear,
+		arg.ReleaseMonth,
+		arg.ReleaseDay,
+		arg.ReleaseGroupMbid,
+		arg.Redirected,
+		arg.Rules,
+	)
+	return err
+}
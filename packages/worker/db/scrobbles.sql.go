@@ -11,6 +11,79 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const createScrobbleStagingTable = `-- name: CreateScrobbleStagingTable :exec
+CREATE TEMP TABLE scrobbles_staging (
+    username varchar(256) NOT NULL,
+    "trackName" varchar(512) NOT NULL,
+    "trackMbid" varchar(36),
+    "artistName" varchar(512) NOT NULL,
+    "artistMbid" varchar(36),
+    "albumName" varchar(512),
+    "albumMbid" varchar(36),
+    "scrobbledAt" timestamp with time zone NOT NULL,
+    "scrobbledAtUnix" varchar(32) NOT NULL,
+    year integer NOT NULL
+) ON COMMIT DROP
+`
+
+// Matches schema/scrobbles_staging.sql; COPY fills it and MergeScrobbleStagingTable empties it into scrobbles
+func (q *Queries) CreateScrobbleStagingTable(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, createScrobbleStagingTable)
+	return err
+}
+
+const getScrobblesForGenreLookup = `-- name: GetScrobblesForGenreLookup :many
+SELECT
+    id,
+    "artistMbid",
+    "matchedReleaseGroupMbid",
+    "matchedRecordingMbid"
+FROM scrobbles
+WHERE username = $1
+  AND year = $2
+  AND "releaseYearFetched" = true
+  AND "genresFetched" = false
+ORDER BY "scrobbledAt"
+`
+
+type GetScrobblesForGenreLookupParams struct {
+	Username string `json:"username"`
+	Year     int32  `json:"year"`
+}
+
+type GetScrobblesForGenreLookupRow struct {
+	ID                      pgtype.UUID `json:"id"`
+	ArtistMbid              pgtype.Text `json:"artistMbid"`
+	MatchedReleaseGroupMbid pgtype.Text `json:"matchedReleaseGroupMbid"`
+	MatchedRecordingMbid    pgtype.Text `json:"matchedRecordingMbid"`
+}
+
+// Genres come from the release year match, so only scrobbles whose release year lookup ran are included
+func (q *Queries) GetScrobblesForGenreLookup(ctx context.Context, arg GetScrobblesForGenreLookupParams) ([]GetScrobblesForGenreLookupRow, error) {
+	rows, err := q.db.Query(ctx, getScrobblesForGenreLookup, arg.Username, arg.Year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetScrobblesForGenreLookupRow{}
+	for rows.Next() {
+		var i GetScrobblesForGenreLookupRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ArtistMbid,
+			&i.MatchedReleaseGroupMbid,
+			&i.MatchedRecordingMbid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getScrobblesForReleaseYearLookup = `-- name: GetScrobblesForReleaseYearLookup :many
 SELECT
     id,
@@ -70,7 +143,7 @@ func (q *Queries) GetScrobblesForReleaseYearLookup(ctx context.Context, arg GetS
 	return items, nil
 }
 
-const insertScrobble = `-- name: InsertScrobble :exec
+const insertScrobble = `-- name: InsertScrobble :execrows
 INSERT INTO scrobbles (
     username,
     "trackName",
@@ -83,6 +156,7 @@ INSERT INTO scrobbles (
     "scrobbledAtUnix",
     year
 ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT ON CONSTRAINT scrobbles_natural_key_unique DO NOTHING
 `
 
 type InsertScrobbleParams struct {
@@ -98,8 +172,9 @@ type InsertScrobbleParams struct {
 	Year            int32              `json:"year"`
 }
 
-func (q *Queries) InsertScrobble(ctx context.Context, arg InsertScrobbleParams) error {
-	_, err := q.db.Exec(ctx, insertScrobble,
+// Fallback for a page whose bulk load fails, so one bad row only skips itself
+func (q *Queries) InsertScrobble(ctx context.Context, arg InsertScrobbleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, insertScrobble,
 		arg.Username,
 		arg.TrackName,
 		arg.TrackMbid,
@@ -111,23 +186,108 @@ func (q *Queries) InsertScrobble(ctx context.Context, arg InsertScrobbleParams)
 		arg.ScrobbledAtUnix,
 		arg.Year,
 	)
-	return err
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const updateScrobbleReleaseYear = `-- name: UpdateScrobbleReleaseYear :exec
+const mergeScrobbleStagingTable = `-- name: MergeScrobbleStagingTable :execrows
+INSERT INTO scrobbles (
+    username,
+    "trackName",
+    "trackMbid",
+    "artistName",
+    "artistMbid",
+    "albumName",
+    "albumMbid",
+    "scrobbledAt",
+    "scrobbledAtUnix",
+    year
+)
+SELECT DISTINCT ON (username, "scrobbledAtUnix", "artistName", "trackName")
+    username,
+    "trackName",
+    "trackMbid",
+    "artistName",
+    "artistMbid",
+    "albumName",
+    "albumMbid",
+    "scrobbledAt",
+    "scrobbledAtUnix",
+    year
+FROM scrobbles_staging
+ON CONFLICT ON CONSTRAINT scrobbles_natural_key_unique DO NOTHING
+`
+
+// Duplicates within a page are collapsed by DISTINCT ON so the merge never conflicts with itself
+func (q *Queries) MergeScrobbleStagingTable(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, mergeScrobbleStagingTable)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateScrobblesGenres = `-- name: UpdateScrobblesGenres :execrows
 UPDATE scrobbles
 SET
-    "releaseYear" = $2,
-    "releaseYearFetched" = true
-WHERE id = $1
+    genres = $1::text[],
+    "genresFetched" = true
+WHERE id = ANY($2::uuid[])
 `
 
-type UpdateScrobbleReleaseYearParams struct {
-	ID          pgtype.UUID `json:"id"`
-	ReleaseYear pgtype.Int4 `json:"releaseYear"`
+type UpdateScrobblesGenresParams struct {
+	Genres []string      `json:"genres"`
+	Ids    []pgtype.UUID `json:"ids"`
 }
 
-func (q *Queries) UpdateScrobbleReleaseYear(ctx context.Context, arg UpdateScrobbleReleaseYearParams) error {
-	_, err := q.db.Exec(ctx, updateScrobbleReleaseYear, arg.ID, arg.ReleaseYear)
-	return err
+func (q *Queries) UpdateScrobblesGenres(ctx context.Context, arg UpdateScrobblesGenresParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateScrobblesGenres, arg.Genres, arg.Ids)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateScrobblesReleaseYear = `-- name: UpdateScrobblesReleaseYear :execrows
+UPDATE scrobbles
+SET
+    "releaseYear" = $1,
+    "releaseMonth" = $2,
+    "releaseDay" = $3,
+    "releaseYearFetched" = true,
+    "releaseYearMethod" = $4,
+    "matchedReleaseGroupMbid" = $5,
+    "matchedRecordingMbid" = $6,
+    "releaseYearConfidence" = $7
+WHERE id = ANY($8::uuid[])
+`
+
+type UpdateScrobblesReleaseYearParams struct {
+	ReleaseYear      pgtype.Int4   `json:"release_year"`
+	ReleaseMonth     pgtype.Int4   `json:"release_month"`
+	ReleaseDay       pgtype.Int4   `json:"release_day"`
+	Method           pgtype.Text   `json:"method"`
+	ReleaseGroupMbid pgtype.Text   `json:"release_group_mbid"`
+	RecordingMbid    pgtype.Text   `json:"recording_mbid"`
+	Confidence       pgtype.Float4 `json:"confidence"`
+	Ids              []pgtype.UUID `json:"ids"`
+}
+
+func (q *Queries) UpdateScrobblesReleaseYear(ctx context.Context, arg UpdateScrobblesReleaseYearParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateScrobblesReleaseYear,
+		arg.ReleaseYear,
+		arg.ReleaseMonth,
+		arg.ReleaseDay,
+		arg.Method,
+		arg.ReleaseGroupMbid,
+		arg.RecordingMbid,
+		arg.Confidence,
+		arg.Ids,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Job event types sent to notifiers
+const (
+	jobEventStarted   = "job.started"
+	jobEventPhase     = "job.phase"
+	jobEventProgress  = "job.progress"
+	jobEventCompleted = "job.completed"
+	jobEventFailed    = "job.failed"
+	jobEventCancelled = "job.cancelled"
+	jobEventSnapshot  = "job.snapshot" // SSE only: current state sent when a stream opens
+)
+
+// JobEvent is a job state transition or progress update
+type JobEvent struct {
+	Type   string    `json:"type"`
+	Job    JobView   `json:"job"`
+	SentAt time.Time `json:"sent_at"`
+}
+
+// JobNotifier receives job events. Notify must not block the calling worker.
+type JobNotifier interface {
+	Notify(event JobEvent)
+}
+
+// JobNotifiers fans a job event out to several notifiers
+type JobNotifiers []JobNotifier
+
+func (n JobNotifiers) Notify(event JobEvent) {
+	for _, notifier := range n {
+		notifier.Notify(event)
+	}
+}
+
+func isTerminalJobEvent(eventType string) bool {
+	return eventType == jobEventCompleted || eventType == jobEventFailed || eventType == jobEventCancelled
+}
+
+// sequencedJobEvent is a job event with its per-job SSE event ID
+type sequencedJobEvent struct {
+	ID    int
+	Event JobEvent
+}
+
+// JobEventBroker keeps a short per-job event history and fans events out to SSE subscribers.
+// Event IDs increase per job within this process, so a client can resume with Last-Event-ID.
+// IDs start again from 1 after a restart, so resuming clients are resynced with a snapshot.
+type JobEventBroker struct {
+	mu          sync.Mutex
+	historySize int
+	retention   time.Duration
+	lastID      map[string]int
+	history     map[string][]sequencedJobEvent
+	subscribers map[string]map[chan sequencedJobEvent]struct{}
+}
+
+var jobEventBroker *JobEventBroker
+
+// NewJobEventBroker keeps up to historySize events per job, dropping a job's history
+// retention after it finishes
+func NewJobEventBroker(historySize int, retention time.Duration) *JobEventBroker {
+	if historySize < 1 {
+		historySize = 1
+	}
+
+	return &JobEventBroker{
+		historySize: historySize,
+		retention:   retention,
+		lastID:      make(map[string]int),
+		history:     make(map[string][]sequencedJobEvent),
+		subscribers: make(map[string]map[chan sequencedJobEvent]struct{}),
+	}
+}
+
+func (b *JobEventBroker) Notify(event JobEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	jobID := event.Job.ID
+	b.lastID[jobID]++
+	sequenced := sequencedJobEvent{ID: b.lastID[jobID], Event: event}
+
+	history := append(b.history[jobID], sequenced)
+	if len(history) > b.historySize {
+		history = history[len(history)-b.historySize:]
+	}
+	b.history[jobID] = history
+
+	for ch := range b.subscribers[jobID] {
+		select {
+		case ch <- sequenced:
+		default:
+			// Slow subscriber: close the stream so the client reconnects with Last-Event-ID
+			delete(b.subscribers[jobID], ch)
+			close(ch)
+		}
+	}
+
+	if isTerminalJobEvent(event.Type) {
+		time.AfterFunc(b.retention, func() { b.forget(jobID) })
+	}
+}
+
+// Subscribe returns the buffered events after lastEventID and a channel for new ones.
+// resync is true when the retained history can't bring the client up to date from
+// lastEventID (a fresh stream, an ID from before a restart, or events already dropped),
+// so the caller should send the current state first.
+// The channel is closed if the subscriber falls behind; call unsubscribe when done.
+func (b *JobEventBroker) Subscribe(jobID string, lastEventID int) ([]sequencedJobEvent, bool, <-chan sequencedJobEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	history := b.history[jobID]
+	if lastEventID > b.lastID[jobID] {
+		// The ID came from an earlier process or a forgotten history, so replay everything
+		lastEventID = 0
+	}
+	resync := lastEventID == 0 || (len(history) > 0 && history[0].ID > lastEventID+1)
+
+	var backlog []sequencedJobEvent
+	for _, event := range history {
+		if event.ID > lastEventID {
+			backlog = append(backlog, event)
+		}
+	}
+
+	ch := make(chan sequencedJobEvent, 64)
+	if b.subscribers[jobID] == nil {
+		b.subscribers[jobID] = make(map[chan sequencedJobEvent]struct{})
+	}
+	b.subscribers[jobID][ch] = struct{}{}
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[jobID][ch]; ok {
+			delete(b.subscribers[jobID], ch)
+			close(ch)
+		}
+		if len(b.subscribers[jobID]) == 0 {
+			delete(b.subscribers, jobID)
+		}
+	}
+
+	return backlog, resync, ch, unsubscribe
+}
+
+func (b *JobEventBroker) forget(jobID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.history, jobID)
+	delete(b.lastID, jobID)
+}
+
+func handleJobEvents(w http.ResponseWriter, r *http.Request) {
+	var id pgtype.UUID
+	if err := id.Scan(r.PathValue("id")); err != nil {
+		respondJSON(w, http.StatusBadRequest, JobResponse{
+			Success: false,
+			Error:   "Invalid job ID",
+		})
+		return
+	}
+
+	job, err := jobQueue.queries.GetFetchJob(r.Context(), id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		respondJSON(w, http.StatusNotFound, JobResponse{
+			Success: false,
+			Error:   "Job not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to get job %s: %v", id, err)
+		respondJSON(w, http.StatusInternalServerError, JobResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondJSON(w, http.StatusInternalServerError, JobResponse{
+			Success: false,
+			Error:   "Streaming not supported",
+		})
+		return
+	}
+
+	// EventSource sends Last-Event-ID on reconnect; the query parameter helps clients that can't set headers
+	lastEventID, _ := strconv.Atoi(r.Header.Get("Last-Event-ID"))
+	if lastEventID == 0 {
+		lastEventID, _ = strconv.Atoi(r.URL.Query().Get("lastEventId"))
+	}
+
+	jobID := id.String()
+	backlog, resync, events, unsubscribe := jobEventBroker.Subscribe(jobID, lastEventID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	// Start with the current state when the history can't fill the gap, so clients don't
+	// wait for the next update or miss events dropped since they last connected
+	if resync {
+		snapshot := JobEvent{Type: jobEventSnapshot, Job: newJobView(job), SentAt: time.Now()}
+		if err := writeSSE(w, 0, snapshot); err != nil {
+			return
+		}
+	}
+
+	for _, event := range backlog {
+		if err := writeSSE(w, event.ID, event.Event); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	// Nothing more will happen for a finished job
+	if job.Status == "completed" || job.Status == "failed" || job.Status == "cancelled" {
+		return
+	}
+	for _, event := range backlog {
+		if isTerminalJobEvent(event.Event.Type) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeSSE(w, event.ID, event.Event); err != nil {
+				return
+			}
+			flusher.Flush()
+			if isTerminalJobEvent(event.Event.Type) {
+				return
+			}
+		}
+	}
+}
+
+// writeSSE writes a single Server-Sent Event. An id of 0 is omitted so it doesn't reset Last-Event-ID.
+func writeSSE(w http.ResponseWriter, id int, event JobEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if id > 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJobEventBrokerSubscribeReplaysAfterLastEventID(t *testing.T) {
+	broker := NewJobEventBroker(10, time.Minute)
+	for _, eventType := range []string{jobEventStarted, jobEventProgress, jobEventProgress} {
+		broker.Notify(JobEvent{Type: eventType, Job: JobView{ID: "job"}})
+	}
+
+	tests := []struct {
+		name        string
+		lastEventID int
+		expectedIDs []int
+	}{
+		{
+			name:        "fresh subscriber gets full history",
+			lastEventID: 0,
+			expectedIDs: []int{1, 2, 3},
+		},
+		{
+			name:        "resume skips delivered events",
+			lastEventID: 2,
+			expectedIDs: []int{3},
+		},
+		{
+			name:        "up to date subscriber gets nothing",
+			lastEventID: 3,
+			expectedIDs: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backlog, _, _, unsubscribe := broker.Subscribe("job", tt.lastEventID)
+			defer unsubscribe()
+
+			var ids []int
+			for _, event := range backlog {
+				ids = append(ids, event.ID)
+			}
+			if len(ids) != len(tt.expectedIDs) {
+				t.Fatalf("got event IDs %v, want %v", ids, tt.expectedIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.expectedIDs[i] {
+					t.Fatalf("got event IDs %v, want %v", ids, tt.expectedIDs)
+				}
+			}
+		})
+	}
+}
+
+func TestJobEventBrokerDeliversLiveEvents(t *testing.T) {
+	broker := NewJobEventBroker(10, time.Minute)
+	_, _, events, unsubscribe := broker.Subscribe("job", 0)
+	defer unsubscribe()
+
+	broker.Notify(JobEvent{Type: jobEventProgress, Job: JobView{ID: "other"}})
+	broker.Notify(JobEvent{Type: jobEventCompleted, Job: JobView{ID: "job"}})
+
+	select {
+	case event := <-events:
+		if event.ID != 1 || event.Event.Type != jobEventCompleted {
+			t.Errorf("got event %d %s, want 1 %s", event.ID, event.Event.Type, jobEventCompleted)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+}
+
+func TestWriteSSE(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	event := JobEvent{Type: jobEventProgress, Job: JobView{ID: "job"}}
+
+	if err := writeSSE(recorder, 7, event); err != nil {
+		t.Fatalf("writeSSE returned error: %v", err)
+	}
+	if err := writeSSE(recorder, 0, event); err != nil {
+		t.Fatalf("writeSSE returned error: %v", err)
+	}
+
+	body := recorder.Body.String()
+	expectedPrefix := "id: 7\nevent: job.progress\ndata: {"
+	if !strings.HasPrefix(body, expectedPrefix) {
+		t.Errorf("unexpected SSE output: %q", body)
+	}
+	if count := strings.Count(body, "id: "); count != 1 {
+		t.Errorf("got %d id lines, want 1 (id 0 must be omitted): %q", count, body)
+	}
+}
+
+func TestJobEventBrokerSubscribeResync(t *testing.T) {
+	broker := NewJobEventBroker(2, time.Minute)
+	for _, eventType := range []string{jobEventStarted, jobEventProgress, jobEventProgress, jobEventProgress} {
+		broker.Notify(JobEvent{Type: eventType, Job: JobView{ID: "job"}})
+	}
+
+	tests := []struct {
+		name           string
+		lastEventID    int
+		expectedResync bool
+		expectedIDs    []int
+	}{
+		{
+			name:           "fresh subscriber",
+			lastEventID:    0,
+			expectedResync: true,
+			expectedIDs:    []int{3, 4},
+		},
+		{
+			name:           "resume inside history",
+			lastEventID:    2,
+			expectedResync: false,
+			expectedIDs:    []int{3, 4},
+		},
+		{
+			name:           "resume before dropped events",
+			lastEventID:    1,
+			expectedResync: true,
+			expectedIDs:    []int{3, 4},
+		},
+		{
+			name:           "ID from before a restart",
+			lastEventID:    40,
+			expectedResync: true,
+			expectedIDs:    []int{3, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backlog, resync, _, unsubscribe := broker.Subscribe("job", tt.lastEventID)
+			defer unsubscribe()
+
+			if resync != tt.expectedResync {
+				t.Errorf("got resync %v, want %v", resync, tt.expectedResync)
+			}
+			var ids []int
+			for _, event := range backlog {
+				ids = append(ids, event.ID)
+			}
+			if len(ids) != len(tt.expectedIDs) {
+				t.Fatalf("got event IDs %v, want %v", ids, tt.expectedIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.expectedIDs[i] {
+					t.Fatalf("got event IDs %v, want %v", ids, tt.expectedIDs)
+				}
+			}
+		})
+	}
+}
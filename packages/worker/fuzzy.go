@@ -0,0 +1,585 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Aliases and sort names identify an artist less directly than its name, so their hits are
+// scored slightly lower and an equally similar primary name wins. Aliases in one of the
+// configured AliasLocales count as much as the name: they are how listeners write it.
+const fuzzyAliasWeight = 0.95
+
+// fuzzyCandidateLimit caps the artists, recordings or release groups fetched from MusicBrainz
+// before they are rescored on normalized names
+const fuzzyCandidateLimit = 50
+
+// artistCandidate is an artist whose name, sort name or alias is similar to the searched name
+type artistCandidate struct {
+	ID      int
+	Name    string
+	Matched string // The name, sort name or alias that matched
+	Source  string // "name", "sort_name", "locale_alias", "alias", "alias_sort_name" or "mbid"
+	Score   float64
+	Part    int // Index of the credited artist it was found for, or -1 for the whole credit
+}
+
+// titleCandidate is a recording or release group credited to some of the artist candidates with a
+// similar title. Score is the title similarity; Combined weighs it by how well the credit matches.
+type titleCandidate struct {
+	ID         int
+	Name       string
+	ArtistIDs  []int // Credited artists among the candidates
+	ArtistName string
+	Score      float64
+	Combined   float64
+	artistRank int
+}
+
+// tryFindReleaseYear ranks artists and then their recordings by trigram similarity and resolves
+// the best recording to its earliest release group. Candidates below FuzzyThreshold are ignored;
+// pgx.ErrNoRows means nothing was similar enough.
+func tryFindReleaseYear(ctx context.Context, artistName, trackName string) (ReleaseYearMatch, error) {
+	artistName = strings.TrimSpace(artistName)
+	trackName = strings.TrimSpace(trackName)
+
+	// Step 1: Rank artists for the whole credit and each credited artist by their best name,
+	// sort name or alias similarity
+	artists, parts, err := findCreditCandidates(ctx, artistName)
+	if err != nil {
+		return ReleaseYearMatch{}, err
+	}
+	if len(artists) == 0 {
+		return ReleaseYearMatch{}, pgx.ErrNoRows
+	}
+
+	return resolveRecordingCandidates(ctx, artists, parts, trackName)
+}
+
+// tryFindReleaseYearByArtistMbid pins the artist Last.fm identified and only matches the recording title,
+// so a common name like "Bush" or "Yes" can't pick up another artist's song. pgx.ErrNoRows means
+// MusicBrainz doesn't know the MBID or no recording title was similar enough.
+func tryFindReleaseYearByArtistMbid(ctx context.Context, artistMbid, trackName string) (ReleaseYearMatch, error) {
+	trackName = strings.TrimSpace(trackName)
+
+	artists, err := findArtistByMbid(ctx, artistMbid)
+	if err != nil {
+		return ReleaseYearMatch{}, err
+	}
+	if len(artists) == 0 {
+		return ReleaseYearMatch{}, pgx.ErrNoRows
+	}
+
+	return resolveRecordingCandidates(ctx, artists, 0, trackName)
+}
+
+// resolveRecordingCandidates ranks the artists' recordings by title similarity and resolves the best one
+// to its earliest release group
+func resolveRecordingCandidates(ctx context.Context, artists []artistCandidate, parts int, trackName string) (ReleaseYearMatch, error) {
+	// Step 2: Rank their recordings by title similarity, weighted by how well the credit matches
+	recordings, err := findRecordingCandidates(ctx, artists, trackName)
+	if err != nil {
+		return ReleaseYearMatch{}, err
+	}
+	recordings = rankTitleCandidates(artists, parts, recordings)
+	if len(recordings) == 0 {
+		return ReleaseYearMatch{}, pgx.ErrNoRows
+	}
+	logTitleCandidates("recording", trackName, recordings)
+
+	// Step 3: The same song is usually several recordings (album, single, live); resolve them together
+	best := recordings[0]
+	var ids []int
+	for _, recording := range recordings {
+		if slices.Equal(recording.ArtistIDs, best.ArtistIDs) && normalizeName(recording.Name) == normalizeName(best.Name) {
+			ids = append(ids, recording.ID)
+		}
+	}
+
+	yearQuery := `
+		SELECT rgm.first_release_date_year, rgm.first_release_date_month, rgm.first_release_date_day, rg.gid::text, r.gid::text
+		FROM musicbrainz.recording r
+		JOIN musicbrainz.track t ON r.id = t.recording
+		JOIN musicbrainz.medium m ON t.medium = m.id
+		JOIN musicbrainz.release rel ON m.release = rel.id
+		JOIN musicbrainz.release_group rg ON rel.release_group = rg.id
+		LEFT JOIN musicbrainz.release_group_meta rgm ON rg.id = rgm.id
+		LEFT JOIN musicbrainz.release_group_primary_type rgpt ON rg.type = rgpt.id
+		WHERE r.id = ANY($1::int[])
+		ORDER BY ` + releaseGroupOrder + `
+		LIMIT 1
+	`
+
+	match := ReleaseYearMatch{Confidence: float32(best.Combined)}
+	err = mbPool.QueryRow(
+		ctx, yearQuery,
+		ids, releaseYearConfig.PreferPrimaryTypes, releaseYearConfig.ExcludedSecondaryTypes,
+	).Scan(&match.Year, &match.Month, &match.Day, &match.ReleaseGroupMbid, &match.RecordingMbid)
+	if err != nil {
+		return ReleaseYearMatch{}, err
+	}
+
+	return match, nil
+}
+
+// tryFindAlbumReleaseYear ranks artists and then their release groups by trigram similarity to the
+// album name. A best match with an excluded secondary type (a compilation, a live album) is returned
+// without a year, since its year isn't the song's. pgx.ErrNoRows means nothing was similar enough.
+func tryFindAlbumReleaseYear(ctx context.Context, artistName, albumName string) (ReleaseYearMatch, error) {
+	artistName = strings.TrimSpace(artistName)
+	albumName = strings.TrimSpace(albumName)
+
+	artists, parts, err := findCreditCandidates(ctx, artistName)
+	if err != nil {
+		return ReleaseYearMatch{}, err
+	}
+	if len(artists) == 0 {
+		return ReleaseYearMatch{}, pgx.ErrNoRows
+	}
+
+	return resolveReleaseGroupCandidates(ctx, artists, parts, albumName)
+}
+
+// tryFindAlbumReleaseYearByArtistMbid pins the artist Last.fm identified and only matches the album title.
+// pgx.ErrNoRows means MusicBrainz doesn't know the MBID or no album title was similar enough.
+func tryFindAlbumReleaseYearByArtistMbid(ctx context.Context, artistMbid, albumName string) (ReleaseYearMatch, error) {
+	albumName = strings.TrimSpace(albumName)
+
+	artists, err := findArtistByMbid(ctx, artistMbid)
+	if err != nil {
+		return ReleaseYearMatch{}, err
+	}
+	if len(artists) == 0 {
+		return ReleaseYearMatch{}, pgx.ErrNoRows
+	}
+
+	return resolveReleaseGroupCandidates(ctx, artists, 0, albumName)
+}
+
+// resolveReleaseGroupCandidates ranks the artists' release groups by title similarity and resolves the
+// best one, returning it without a year when it is an excluded type
+func resolveReleaseGroupCandidates(ctx context.Context, artists []artistCandidate, parts int, albumName string) (ReleaseYearMatch, error) {
+	releaseGroups, err := findReleaseGroupCandidates(ctx, artists, albumName)
+	if err != nil {
+		return ReleaseYearMatch{}, err
+	}
+	releaseGroups = rankTitleCandidates(artists, parts, releaseGroups)
+	if len(releaseGroups) == 0 {
+		return ReleaseYearMatch{}, pgx.ErrNoRows
+	}
+	logTitleCandidates("release group", albumName, releaseGroups)
+
+	// Editions of one album are often separate release groups; the earliest eligible one wins
+	best := releaseGroups[0]
+	var ids []int
+	for _, releaseGroup := range releaseGroups {
+		if slices.Equal(releaseGroup.ArtistIDs, best.ArtistIDs) && normalizeName(releaseGroup.Name) == normalizeName(best.Name) {
+			ids = append(ids, releaseGroup.ID)
+		}
+	}
+
+	yearQuery := `
+		SELECT
+			rgm.first_release_date_year,
+			rgm.first_release_date_month,
+			rgm.first_release_date_day,
+			rg.gid::text,
+			EXISTS (
+				SELECT 1
+				FROM musicbrainz.release_group_secondary_type_join rgst
+				JOIN musicbrainz.release_group_secondary_type st ON rgst.secondary_type = st.id
+				WHERE rgst.release_group = rg.id
+				  AND lower(st.name) = ANY($3::text[])
+			)
+		FROM musicbrainz.release_group rg
+		LEFT JOIN musicbrainz.release_group_meta rgm ON rg.id = rgm.id
+		LEFT JOIN musicbrainz.release_group_primary_type rgpt ON rg.type = rgpt.id
+		WHERE rg.id = ANY($1::int[])
+		ORDER BY ` + releaseGroupOrder + `
+		LIMIT 1
+	`
+
+	match := ReleaseYearMatch{Confidence: float32(best.Combined)}
+	var excluded bool
+	err = mbPool.QueryRow(
+		ctx, yearQuery,
+		ids, releaseYearConfig.PreferPrimaryTypes, releaseYearConfig.ExcludedSecondaryTypes,
+	).Scan(&match.Year, &match.Month, &match.Day, &match.ReleaseGroupMbid, &excluded)
+	if err != nil {
+		return ReleaseYearMatch{}, err
+	}
+
+	if excluded {
+		log.Printf("Release group %s for album '%s' is an excluded type, falling back to the track", match.ReleaseGroupMbid.String, albumName)
+		match.Year, match.Month, match.Day = pgtype.Int4{}, pgtype.Int4{}, pgtype.Int4{}
+	}
+
+	return match, nil
+}
+
+// findCreditCandidates searches for the whole artist credit and, for a collaboration, for each credited
+// artist on its own. A band called "Simon & Garfunkel" is found by the first search, "Calvin Harris &
+// Dua Lipa" by the second. It returns the candidates and the number of credited artists searched.
+func findCreditCandidates(ctx context.Context, artistName string) ([]artistCandidate, int, error) {
+	artists, err := findArtistCandidates(ctx, artistName)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range artists {
+		artists[i].Part = -1
+	}
+	if len(artists) > 0 {
+		logArtistCandidates(artistName, artists)
+	}
+
+	parts := splitArtistCredit(artistName)
+	if len(parts) < 2 {
+		return artists, 0, nil
+	}
+
+	for i, part := range parts {
+		found, err := findArtistCandidates(ctx, part)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(found) == 0 {
+			continue
+		}
+		logArtistCandidates(part, found)
+		for _, artist := range found {
+			artist.Part = i
+			artists = append(artists, artist)
+		}
+	}
+
+	return artists, len(parts), nil
+}
+
+// findArtistByMbid returns the artist with the given MBID as the only candidate, scored as an exact
+// match. The MBID of an artist MusicBrainz has since merged resolves through the redirect. It returns
+// no candidates for an MBID that isn't a UUID or isn't in the mirror.
+func findArtistByMbid(ctx context.Context, artistMbid string) ([]artistCandidate, error) {
+	var gid pgtype.UUID
+	if err := gid.Scan(strings.TrimSpace(artistMbid)); err != nil {
+		log.Printf("Ignoring malformed artist MBID '%s': %v", artistMbid, err)
+		return nil, nil
+	}
+
+	query := `
+		WITH target AS (
+			SELECT id, false AS redirected FROM musicbrainz.artist WHERE gid = $1
+			UNION ALL
+			SELECT new_id, true FROM musicbrainz.artist_gid_redirect WHERE gid = $1
+		)
+		SELECT a.id, a.name, target.redirected
+		FROM target
+		JOIN musicbrainz.artist a ON a.id = target.id
+		ORDER BY target.redirected
+		LIMIT 1
+	`
+
+	artist := artistCandidate{Matched: artistMbid, Source: "mbid", Score: 1, Part: -1}
+	var redirected bool
+	err := mbPool.QueryRow(ctx, query, gid).Scan(&artist.ID, &artist.Name, &redirected)
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Artist MBID %s is not in MusicBrainz", artistMbid)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if redirected {
+		log.Printf("Pinned artist '%s' (ID: %d) through the redirect of merged artist MBID %s", artist.Name, artist.ID, artistMbid)
+	} else {
+		log.Printf("Pinned artist '%s' (ID: %d) by artist MBID %s", artist.Name, artist.ID, artistMbid)
+	}
+	return []artistCandidate{artist}, nil
+}
+
+// findArtistCandidates returns up to FuzzyCandidates artists, best first. Both sides are folded
+// (NFKC, lowercase, no accents) before the % operator, so "Bjork" or full-width input isn't lost
+// before rescoring. It uses the GIN trigram indexes on musicbrainz.lastyearfm_fold with
+// pg_trgm.similarity_threshold, set per connection. The folded similarity only picks the
+// candidates; they are ranked on normalized names.
+func findArtistCandidates(ctx context.Context, artistName string) ([]artistCandidate, error) {
+	query := `
+		SELECT c.artist, a.name, c.matched, c.source, c.score
+		FROM (
+			SELECT DISTINCT ON (hit.artist) hit.artist, hit.matched, hit.source, hit.score
+			FROM (
+				SELECT a.id AS artist, a.name AS matched, 'name' AS source, similarity(musicbrainz.lastyearfm_fold(a.name), $1) AS score
+				FROM musicbrainz.artist a
+				WHERE musicbrainz.lastyearfm_fold(a.name) % $1
+				UNION ALL
+				SELECT a.id, a.sort_name, 'sort_name', similarity(musicbrainz.lastyearfm_fold(a.sort_name), $1) * $2
+				FROM musicbrainz.artist a
+				WHERE musicbrainz.lastyearfm_fold(a.sort_name) % $1
+				UNION ALL
+				SELECT
+					aa.artist,
+					aa.name,
+					CASE WHEN split_part(aa.locale, '_', 1) = ANY($4::text[]) THEN 'locale_alias' ELSE 'alias' END,
+					similarity(musicbrainz.lastyearfm_fold(aa.name), $1) * CASE WHEN split_part(aa.locale, '_', 1) = ANY($4::text[]) THEN 1 ELSE $2 END
+				FROM musicbrainz.artist_alias aa
+				WHERE musicbrainz.lastyearfm_fold(aa.name) % $1
+				UNION ALL
+				SELECT aa.artist, aa.sort_name, 'alias_sort_name', similarity(musicbrainz.lastyearfm_fold(aa.sort_name), $1) * $2
+				FROM musicbrainz.artist_alias aa
+				WHERE musicbrainz.lastyearfm_fold(aa.sort_name) % $1
+			) hit
+			ORDER BY hit.artist, hit.score DESC
+		) c
+		JOIN musicbrainz.artist a ON a.id = c.artist
+		ORDER BY c.score DESC, c.artist
+		LIMIT $3
+	`
+
+	rows, err := mbPool.Query(ctx, query, foldSearchName(artistName), fuzzyAliasWeight, fuzzyCandidateLimit, releaseYearConfig.AliasLocales)
+	if err != nil {
+		return nil, err
+	}
+
+	artists, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (artistCandidate, error) {
+		var c artistCandidate
+		err := row.Scan(&c.ID, &c.Name, &c.Matched, &c.Source, &c.Score)
+		return c, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rescoreArtistCandidates(artistName, artists, releaseYearConfig.FuzzyThreshold, releaseYearConfig.FuzzyCandidates), nil
+}
+
+// findRecordingCandidates returns recordings credited to any of the artists with a title above the threshold,
+// compared on normalized names
+func findRecordingCandidates(ctx context.Context, artists []artistCandidate, trackName string) ([]titleCandidate, error) {
+	artistIDs := candidateArtistIDs(artists)
+
+	query := `
+		SELECT r.id, r.name, array_agg(DISTINCT acn.artist ORDER BY acn.artist), similarity(musicbrainz.lastyearfm_fold(r.name), $2) AS score
+		FROM musicbrainz.recording r
+		JOIN musicbrainz.artist_credit_name acn ON r.artist_credit = acn.artist_credit
+		WHERE acn.artist = ANY($1::int[])
+			AND musicbrainz.lastyearfm_fold(r.name) % $2
+		GROUP BY r.id, r.name
+		ORDER BY score DESC, r.id
+		LIMIT $3
+	`
+
+	rows, err := mbPool.Query(ctx, query, artistIDs, foldSearchName(trackName), fuzzyCandidateLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	titles, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (titleCandidate, error) {
+		var c titleCandidate
+		err := row.Scan(&c.ID, &c.Name, &c.ArtistIDs, &c.Score)
+		return c, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rescoreTitleCandidates(trackName, titles, releaseYearConfig.FuzzyThreshold), nil
+}
+
+// findReleaseGroupCandidates returns release groups credited to any of the artists with a title above the threshold,
+// compared on normalized names
+func findReleaseGroupCandidates(ctx context.Context, artists []artistCandidate, albumName string) ([]titleCandidate, error) {
+	artistIDs := candidateArtistIDs(artists)
+
+	query := `
+		SELECT rg.id, rg.name, array_agg(DISTINCT acn.artist ORDER BY acn.artist), similarity(musicbrainz.lastyearfm_fold(rg.name), $2) AS score
+		FROM musicbrainz.release_group rg
+		JOIN musicbrainz.artist_credit_name acn ON rg.artist_credit = acn.artist_credit
+		WHERE acn.artist = ANY($1::int[])
+			AND musicbrainz.lastyearfm_fold(rg.name) % $2
+		GROUP BY rg.id, rg.name
+		ORDER BY score DESC, rg.id
+		LIMIT $3
+	`
+
+	rows, err := mbPool.Query(ctx, query, artistIDs, foldSearchName(albumName), fuzzyCandidateLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	titles, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (titleCandidate, error) {
+		var c titleCandidate
+		err := row.Scan(&c.ID, &c.Name, &c.ArtistIDs, &c.Score)
+		return c, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rescoreTitleCandidates(albumName, titles, releaseYearConfig.FuzzyThreshold), nil
+}
+
+func candidateArtistIDs(artists []artistCandidate) []int {
+	ids := make([]int, 0, len(artists))
+	for _, artist := range artists {
+		ids = append(ids, artist.ID)
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+// rescoreArtistCandidates raises each artist's score to the similarity of the normalized names, so
+// "Beyonce" finds "Beyoncé" as well as MusicBrainz's trigrams allow. Aliases outside AliasLocales and
+// sort names keep their lower weight. Artists below threshold are dropped and at most limit are kept, best first.
+func rescoreArtistCandidates(search string, artists []artistCandidate, threshold float64, limit int) []artistCandidate {
+	normalizedSearch := normalizeName(search)
+
+	kept := make([]artistCandidate, 0, len(artists))
+	for _, artist := range artists {
+		weight := 1.0
+		if artist.Source != "name" && artist.Source != "locale_alias" {
+			weight = fuzzyAliasWeight
+		}
+		artist.Score = max(artist.Score, weight*trigramSimilarity(normalizeName(artist.Matched), normalizedSearch))
+		if artist.Score >= threshold {
+			kept = append(kept, artist)
+		}
+	}
+
+	slices.SortStableFunc(kept, func(a, b artistCandidate) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		default:
+			return a.ID - b.ID
+		}
+	})
+	return kept[:min(len(kept), limit)]
+}
+
+// rescoreTitleCandidates raises each title's score to the similarity of the normalized titles
+// and drops titles below threshold
+func rescoreTitleCandidates(search string, titles []titleCandidate, threshold float64) []titleCandidate {
+	normalizedSearch := normalizeName(search)
+
+	kept := make([]titleCandidate, 0, len(titles))
+	for _, title := range titles {
+		title.Score = max(title.Score, trigramSimilarity(normalizeName(title.Name), normalizedSearch))
+		if title.Score >= threshold {
+			kept = append(kept, title)
+		}
+	}
+	return kept
+}
+
+// rankTitleCandidates scores each title by its similarity times its credit score and sorts them
+// best first. Ties go to the better-ranked artist, then the lowest ID, so the same search always
+// picks the same recording or release group.
+func rankTitleCandidates(artists []artistCandidate, parts int, titles []titleCandidate) []titleCandidate {
+	ranked := make([]titleCandidate, 0, len(titles))
+	for _, title := range titles {
+		score, rank, names := creditScore(artists, parts, title.ArtistIDs)
+		if score == 0 {
+			continue
+		}
+		title.ArtistName = strings.Join(names, " & ")
+		title.Combined = title.Score * score
+		title.artistRank = rank
+		ranked = append(ranked, title)
+	}
+
+	slices.SortStableFunc(ranked, func(a, b titleCandidate) int {
+		switch {
+		case a.Combined != b.Combined:
+			if a.Combined > b.Combined {
+				return -1
+			}
+			return 1
+		case a.artistRank != b.artistRank:
+			return a.artistRank - b.artistRank
+		default:
+			return a.ID - b.ID
+		}
+	})
+	return ranked
+}
+
+// creditScore rates how well a title's credited artists match the searched credit. A hit on the
+// whole credit scores that artist's score. Otherwise each of the parts credited artists counts with
+// its best candidate: the average of the credited ones, scaled by (1 + matched) / (1 + parts), so
+// a recording by both collaborators beats one by either alone and extra credited artists don't hurt.
+// It also returns the best rank among the credited candidates and their names for logging.
+func creditScore(artists []artistCandidate, parts int, credited []int) (float64, int, []string) {
+	whole := 0.0
+	best := make([]float64, parts)
+	rank := len(artists)
+	var names []string
+
+	for i, artist := range artists {
+		if !slices.Contains(credited, artist.ID) {
+			continue
+		}
+		rank = min(rank, i)
+		if !slices.Contains(names, artist.Name) {
+			names = append(names, artist.Name)
+		}
+		if artist.Part < 0 {
+			whole = max(whole, artist.Score)
+		} else {
+			best[artist.Part] = max(best[artist.Part], artist.Score)
+		}
+	}
+
+	matched, sum := 0, 0.0
+	for _, score := range best {
+		if score > 0 {
+			matched++
+			sum += score
+		}
+	}
+	if matched == 0 {
+		return whole, rank, names
+	}
+
+	partial := sum / float64(matched) * float64(1+matched) / float64(1+parts)
+	return max(whole, partial), rank, names
+}
+
+// Runner-ups are logged so surprising matches can be traced back to what else was considered
+const fuzzyLoggedRunnerUps = 3
+
+func logArtistCandidates(search string, artists []artistCandidate) {
+	best := artists[0]
+	log.Printf("Found artist '%s' (ID: %d, %s '%s', score %.2f) for search '%s'",
+		best.Name, best.ID, best.Source, best.Matched, best.Score, search)
+
+	var runnerUps []string
+	for _, artist := range artists[1:min(len(artists), fuzzyLoggedRunnerUps+1)] {
+		runnerUps = append(runnerUps, fmt.Sprintf("'%s' (ID: %d, %s, %.2f)", artist.Name, artist.ID, artist.Source, artist.Score))
+	}
+	if len(runnerUps) > 0 {
+		log.Printf("Artist runner-ups for '%s': %s", search, strings.Join(runnerUps, ", "))
+	}
+}
+
+func logTitleCandidates(kind, search string, titles []titleCandidate) {
+	best := titles[0]
+	log.Printf("Found %s '%s' by '%s' (ID: %d, score %.2f) for search '%s'",
+		kind, best.Name, best.ArtistName, best.ID, best.Combined, search)
+
+	var runnerUps []string
+	for _, title := range titles[1:min(len(titles), fuzzyLoggedRunnerUps+1)] {
+		runnerUps = append(runnerUps, fmt.Sprintf("'%s' by '%s' (ID: %d, %.2f)", title.Name, title.ArtistName, title.ID, title.Combined))
+	}
+	if len(runnerUps) > 0 {
+		log.Printf("%s runner-ups for '%s': %s", strings.ToUpper(kind[:1])+kind[1:], search, strings.Join(runnerUps, ", "))
+	}
+}
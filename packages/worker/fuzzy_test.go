@@ -0,0 +1,175 @@
+package main
+
+import "testing"
+
+func TestRescoreArtistCandidates(t *testing.T) {
+	artists := []artistCandidate{
+		{ID: 1, Name: "Beyoncé", Matched: "Beyoncé", Source: "name", Score: 0.6},
+		{ID: 2, Name: "Beyond", Matched: "Beyond", Source: "name", Score: 0.55},
+		{ID: 3, Name: "Queen B", Matched: "Beyonce", Source: "alias", Score: 0.95},
+		{ID: 4, Name: "Bey", Matched: "Bey", Source: "name", Score: 0.3},
+	}
+
+	tests := []struct {
+		name      string
+		threshold float64
+		limit     int
+		expected  []int
+	}{
+		{
+			name:      "normalized name match beats an alias and a weaker spelling",
+			threshold: 0.5,
+			limit:     5,
+			expected:  []int{1, 3, 2},
+		},
+		{
+			name:      "threshold drops weak candidates",
+			threshold: 0.9,
+			limit:     5,
+			expected:  []int{1, 3},
+		},
+		{
+			name:      "limit keeps the best",
+			threshold: 0.5,
+			limit:     1,
+			expected:  []int{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := rescoreArtistCandidates("Beyonce", artists, tt.threshold, tt.limit)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("got %d artists, want %d", len(result), len(tt.expected))
+			}
+			for i, artist := range result {
+				if artist.ID != tt.expected[i] {
+					t.Errorf("rank %d = artist %d, want %d", i, artist.ID, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRankTitleCandidates(t *testing.T) {
+	artists := []artistCandidate{
+		{ID: 10, Name: "Yes", Score: 1.0, Part: -1},
+		{ID: 20, Name: "Yes Sir", Score: 0.5, Part: -1},
+	}
+
+	tests := []struct {
+		name       string
+		recordings []titleCandidate
+		expected   []int
+	}{
+		{
+			name: "artist score weighs the title score",
+			recordings: []titleCandidate{
+				{ID: 1, ArtistIDs: []int{20}, Score: 1.0},
+				{ID: 2, ArtistIDs: []int{10}, Score: 0.8},
+			},
+			expected: []int{2, 1},
+		},
+		{
+			name: "ties go to the better-ranked artist",
+			recordings: []titleCandidate{
+				{ID: 1, ArtistIDs: []int{20}, Score: 1.0},
+				{ID: 2, ArtistIDs: []int{10}, Score: 0.5},
+			},
+			expected: []int{2, 1},
+		},
+		{
+			name: "equal scores for one artist go to the lowest recording ID",
+			recordings: []titleCandidate{
+				{ID: 7, ArtistIDs: []int{10}, Score: 0.9},
+				{ID: 3, ArtistIDs: []int{10}, Score: 0.9},
+				{ID: 5, ArtistIDs: []int{10}, Score: 1.0},
+			},
+			expected: []int{5, 3, 7},
+		},
+		{
+			name: "recordings by artists that aren't candidates are dropped",
+			recordings: []titleCandidate{
+				{ID: 1, ArtistIDs: []int{30}, Score: 1.0},
+				{ID: 2, ArtistIDs: []int{10}, Score: 0.6},
+			},
+			expected: []int{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranked := rankTitleCandidates(artists, 0, tt.recordings)
+			if len(ranked) != len(tt.expected) {
+				t.Fatalf("got %d recordings, want %d", len(ranked), len(tt.expected))
+			}
+			for i, recording := range ranked {
+				if recording.ID != tt.expected[i] {
+					t.Errorf("rank %d = recording %d, want %d", i, recording.ID, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRankTitleCandidatesForCollaborations(t *testing.T) {
+	// "Calvin Harris & Dua Lipa": no artist is called that, so only the parts have candidates
+	artists := []artistCandidate{
+		{ID: 10, Name: "Calvin Harris", Score: 1.0, Part: 0},
+		{ID: 20, Name: "Dua Lipa", Score: 1.0, Part: 1},
+		{ID: 30, Name: "Calvin Harriss", Score: 0.8, Part: 0},
+	}
+
+	tests := []struct {
+		name       string
+		recordings []titleCandidate
+		expected   []int
+	}{
+		{
+			name: "full credit beats a single collaborator",
+			recordings: []titleCandidate{
+				{ID: 1, ArtistIDs: []int{10}, Score: 1.0},
+				{ID: 2, ArtistIDs: []int{10, 20}, Score: 1.0},
+			},
+			expected: []int{2, 1},
+		},
+		{
+			name: "superset of the credit counts as the full credit",
+			recordings: []titleCandidate{
+				{ID: 1, ArtistIDs: []int{10, 20}, Score: 0.9},
+				{ID: 2, ArtistIDs: []int{10, 20, 99}, Score: 1.0},
+			},
+			expected: []int{2, 1},
+		},
+		{
+			name: "a much better title by one collaborator still wins",
+			recordings: []titleCandidate{
+				{ID: 1, ArtistIDs: []int{10, 20}, Score: 0.5},
+				{ID: 2, ArtistIDs: []int{20}, Score: 1.0},
+			},
+			expected: []int{2, 1},
+		},
+		{
+			name: "weaker artist candidates score lower",
+			recordings: []titleCandidate{
+				{ID: 1, ArtistIDs: []int{30, 20}, Score: 1.0},
+				{ID: 2, ArtistIDs: []int{10, 20}, Score: 1.0},
+			},
+			expected: []int{2, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranked := rankTitleCandidates(artists, 2, tt.recordings)
+			if len(ranked) != len(tt.expected) {
+				t.Fatalf("got %d recordings, want %d", len(ranked), len(tt.expected))
+			}
+			for i, recording := range ranked {
+				if recording.ID != tt.expected[i] {
+					t.Errorf("rank %d = recording %d, want %d", i, recording.ID, tt.expected[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,396 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"last-year-fm/worker/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Genre lookup phase, reported on fetch_jobs.phase after the release year phases
+const genrePhase = "genres"
+
+// Tag votes on the recording say the most about a song, votes on its artists the least
+const (
+	genreWeightRecording    = 3
+	genreWeightReleaseGroup = 2
+	genreWeightArtist       = 1
+)
+
+// GenreConfig bounds the genre lookups and how many genres each scrobble keeps
+type GenreConfig struct {
+	Concurrency int
+	Timeout     time.Duration
+	Limit       int           // Genres stored per scrobble, best first
+	CacheTTL    time.Duration // Tags keep getting votes, so cached genres are looked up again after this
+}
+
+// genreConfig is loaded in main once the environment is read
+var genreConfig GenreConfig
+
+func loadGenreConfig() GenreConfig {
+	return GenreConfig{
+		Concurrency: envInt("GENRE_WORKERS", 10),
+		Timeout:     envDuration("GENRE_TIMEOUT", 5*time.Second),
+		Limit:       max(envInt("GENRE_LIMIT", 3), 1),
+		CacheTTL:    envDuration("GENRE_CACHE_TTL", 30*24*time.Hour),
+	}
+}
+
+// Rules identifies the genre ranking settings, so cached genres ranked under other settings are ignored
+func (c GenreConfig) Rules() string {
+	return fmt.Sprintf("genres;weights=%d,%d,%d;limit=%d",
+		genreWeightRecording, genreWeightReleaseGroup, genreWeightArtist, c.Limit)
+}
+
+// GenreStats summarizes a genre lookup run
+type GenreStats struct {
+	Phase     string
+	Total     int
+	Processed int
+	Found     int // Scrobbles that got at least one genre
+	NotFound  int
+	TimedOut  int // Left with genresFetched = false so the next run retries them
+	Errors    int // Failed lookups, also left for the next run
+	CacheHits int // Keys answered from genre_cache without querying MusicBrainz
+}
+
+// genreCounters are the lookup counters shared by the lookup workers
+type genreCounters struct {
+	processed atomic.Int64
+	found     atomic.Int64
+	notFound  atomic.Int64
+	timedOut  atomic.Int64
+	errors    atomic.Int64
+	cacheHits atomic.Int64
+}
+
+func (c *genreCounters) snapshot(total int) GenreStats {
+	return GenreStats{
+		Phase:     genrePhase,
+		Total:     total,
+		Processed: int(c.processed.Load()),
+		Found:     int(c.found.Load()),
+		NotFound:  int(c.notFound.Load()),
+		TimedOut:  int(c.timedOut.Load()),
+		Errors:    int(c.errors.Load()),
+		CacheHits: int(c.cacheHits.Load()),
+	}
+}
+
+// genreGroup is a set of scrobbles matched to the same MusicBrainz entities. The genres are
+// looked up once using the first scrobble and written to every scrobble in the group.
+type genreGroup struct {
+	Key      string
+	Scrobble db.GetScrobblesForGenreLookupRow
+	IDs      []pgtype.UUID
+}
+
+// genreSources returns the MBIDs a scrobble's genres come from. The Last.fm artist MBID is only
+// used when the release year lookup matched nothing, since a match brings its own credited artists.
+func genreSources(scrobble db.GetScrobblesForGenreLookupRow) (recording, releaseGroup, artist string) {
+	recording = strings.ToLower(scrobble.MatchedRecordingMbid.String)
+	releaseGroup = strings.ToLower(scrobble.MatchedReleaseGroupMbid.String)
+	if recording == "" && releaseGroup == "" {
+		artist = strings.ToLower(strings.TrimSpace(scrobble.ArtistMbid.String))
+	}
+	return recording, releaseGroup, artist
+}
+
+// genreKey joins the genre sources with a NUL, or returns "" for a scrobble without any
+func genreKey(scrobble db.GetScrobblesForGenreLookupRow) string {
+	recording, releaseGroup, artist := genreSources(scrobble)
+	if recording == "" && releaseGroup == "" && artist == "" {
+		return ""
+	}
+	return recording + "\x00" + releaseGroup + "\x00" + artist
+}
+
+// groupGenreScrobbles groups scrobbles by genre key in first-seen order. Scrobbles without a key
+// have nothing to look up and are returned separately.
+func groupGenreScrobbles(scrobbles []db.GetScrobblesForGenreLookupRow) ([]genreGroup, []pgtype.UUID) {
+	var groups []genreGroup
+	var unmatched []pgtype.UUID
+	index := make(map[string]int)
+
+	for _, scrobble := range scrobbles {
+		k := genreKey(scrobble)
+		if k == "" {
+			unmatched = append(unmatched, scrobble.ID)
+			continue
+		}
+
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, genreGroup{Key: k, Scrobble: scrobble})
+		}
+		groups[i].IDs = append(groups[i].IDs, scrobble.ID)
+	}
+
+	return groups, unmatched
+}
+
+// findGenresForScrobbles stores the top genres for a user's scrobbles whose release year lookup has run,
+// calling onProgress periodically. Scrobbles matched to the same recording and release group share a
+// lookup, and keys already in genre_cache skip MusicBrainz. queries must be backed by a pool, the
+// workers update scrobbles concurrently.
+func findGenresForScrobbles(ctx context.Context, queries *db.Queries, username string, year int, onProgress func(GenreStats)) (GenreStats, error) {
+	log.Printf("Starting genre lookup for user '%s', year %d", username, year)
+
+	config := genreConfig
+	var counters genreCounters
+	var stats GenreStats
+	var total int
+
+	// Workers report concurrently, onProgress sees one snapshot at a time
+	var progressMu sync.Mutex
+	reportProgress := func() {
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		stats = counters.snapshot(total)
+		if onProgress != nil {
+			onProgress(stats)
+		}
+	}
+
+	// addProcessed counts updated scrobbles, reporting progress every 100
+	addProcessed := func(n int) {
+		processed := counters.processed.Add(int64(n))
+		if processed/100 != (processed-int64(n))/100 {
+			log.Printf("Progress: %d/%d scrobbles processed", processed, total)
+			reportProgress()
+		}
+	}
+
+	cache := newGenreCache(queries, config.CacheTTL, config.Rules())
+
+	scrobbles, err := queries.GetScrobblesForGenreLookup(ctx, db.GetScrobblesForGenreLookupParams{
+		Username: username,
+		Year:     int32(year),
+	})
+	if err != nil {
+		return stats, fmt.Errorf("failed to get scrobbles: %w", err)
+	}
+
+	log.Printf("Found %d scrobbles to look up genres for", len(scrobbles))
+	total = len(scrobbles)
+	reportProgress()
+	startTime := time.Now()
+
+	// updateScrobbles writes genres to scrobbles; no genres is recorded as an empty list
+	updateScrobbles := func(key string, ids []pgtype.UUID, genres []string) (int, error) {
+		if genres == nil {
+			genres = []string{}
+		}
+		updated, err := queries.UpdateScrobblesGenres(ctx, db.UpdateScrobblesGenresParams{
+			Genres: genres,
+			Ids:    ids,
+		})
+		if err != nil {
+			log.Printf("Failed to update genres of %d scrobbles for key %q: %v", len(ids), key, err)
+			return 0, err
+		}
+		return int(updated), nil
+	}
+
+	groups, unmatched := groupGenreScrobbles(scrobbles)
+
+	// Scrobbles the release year lookup couldn't match have no entities to take genres from
+	if len(unmatched) > 0 {
+		updated, err := updateScrobbles("", unmatched, nil)
+		if err != nil {
+			return stats, fmt.Errorf("failed to update unmatched scrobbles: %w", err)
+		}
+		counters.notFound.Add(int64(updated))
+		addProcessed(updated)
+	}
+
+	log.Printf("Looking up genres for %d distinct matches on %d workers...", len(groups), config.Concurrency)
+	cached := cache.load(ctx, groups)
+	runLookupPool(ctx, config.Concurrency, len(groups), func(i int) {
+		group := groups[i]
+
+		genres, ok := cached[group.Key]
+		if ok {
+			counters.cacheHits.Add(1)
+		} else {
+			lookupCtx, cancel := lookupContext(ctx, config.Timeout)
+			defer cancel()
+
+			recording, releaseGroup, artist := genreSources(group.Scrobble)
+			var err error
+			genres, err = findGenres(lookupCtx, recording, releaseGroup, artist, config.Limit)
+
+			// An interrupted or failed lookup must not mark the scrobbles as fetched without genres
+			if ctx.Err() != nil {
+				return
+			}
+			if errors.Is(lookupCtx.Err(), context.DeadlineExceeded) {
+				log.Printf("Genre lookup for %q timed out after %v", group.Key, config.Timeout)
+				counters.timedOut.Add(int64(len(group.IDs)))
+				return
+			}
+			if err != nil {
+				log.Printf("Genre lookup for %q failed: %v", group.Key, err)
+				counters.errors.Add(int64(len(group.IDs)))
+				return
+			}
+
+			cache.store(ctx, group.Key, genres)
+		}
+
+		updated, err := updateScrobbles(group.Key, group.IDs, genres)
+		if err != nil {
+			return
+		}
+
+		if len(genres) > 0 {
+			counters.found.Add(int64(updated))
+		} else {
+			counters.notFound.Add(int64(updated))
+		}
+		addProcessed(updated)
+	})
+	if ctx.Err() != nil {
+		reportProgress()
+		return stats, fmt.Errorf("genre lookup stopped: %w", ctx.Err())
+	}
+
+	reportProgress()
+
+	log.Printf("Genre lookup complete: processed=%d, found=%d, not_found=%d, timed_out=%d, errors=%d, cache_hits=%d (took %v)",
+		stats.Processed, stats.Found, stats.NotFound, stats.TimedOut, stats.Errors, stats.CacheHits, time.Since(startTime))
+	return stats, nil
+}
+
+// findGenres returns up to limit genres for a recording, its release group and their credited artists,
+// or for just an artist. Tags are only kept when their name is on MusicBrainz's curated genre list, and
+// their vote counts are weighted by how specific the tagged entity is. Empty MBIDs are skipped.
+func findGenres(ctx context.Context, recordingMbid, releaseGroupMbid, artistMbid string, limit int) ([]string, error) {
+	query := `
+		WITH recording AS (
+			SELECT id, artist_credit FROM musicbrainz.recording WHERE gid = $1
+		), release_group AS (
+			SELECT id, artist_credit FROM musicbrainz.release_group WHERE gid = $2
+		), artist AS (
+			SELECT acn.artist AS id
+			FROM musicbrainz.artist_credit_name acn
+			WHERE acn.artist_credit IN (
+				SELECT artist_credit FROM recording
+				UNION
+				SELECT artist_credit FROM release_group
+			)
+			UNION
+			SELECT id FROM musicbrainz.artist WHERE gid = $3
+		), votes AS (
+			SELECT rt.tag, rt.count * $4 AS score
+			FROM musicbrainz.recording_tag rt
+			JOIN recording ON rt.recording = recording.id
+			UNION ALL
+			SELECT rgt.tag, rgt.count * $5
+			FROM musicbrainz.release_group_tag rgt
+			JOIN release_group ON rgt.release_group = release_group.id
+			UNION ALL
+			SELECT at.tag, at.count * $6
+			FROM musicbrainz.artist_tag at
+			JOIN artist ON at.artist = artist.id
+		)
+		SELECT g.name
+		FROM votes
+		JOIN musicbrainz.tag t ON t.id = votes.tag
+		JOIN musicbrainz.genre g ON g.name = t.name
+		GROUP BY g.name
+		HAVING sum(votes.score) > 0
+		ORDER BY sum(votes.score) DESC, g.name
+		LIMIT $7
+	`
+
+	rows, err := mbPool.Query(
+		ctx, query,
+		parseMbid(recordingMbid), parseMbid(releaseGroupMbid), parseMbid(artistMbid),
+		genreWeightRecording, genreWeightReleaseGroup, genreWeightArtist, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// parseMbid turns an MBID into a query parameter; an empty or malformed MBID becomes NULL, which matches nothing
+func parseMbid(mbid string) pgtype.UUID {
+	var id pgtype.UUID
+	if err := id.Scan(mbid); err != nil {
+		return pgtype.UUID{}
+	}
+	return id
+}
+
+// genreCache reads and writes genre_cache in the app database, keyed like genreGroup.Key.
+// Cache failures are logged and treated as misses so enrichment never depends on the cache.
+type genreCache struct {
+	queries    *db.Queries
+	freshSince pgtype.Timestamptz
+	rules      pgtype.Text
+}
+
+// newGenreCache ignores entries older than ttl and entries ranked under other rules
+func newGenreCache(queries *db.Queries, ttl time.Duration, rules string) *genreCache {
+	return &genreCache{
+		queries:    queries,
+		freshSince: pgtype.Timestamptz{Time: time.Now().Add(-ttl), Valid: true},
+		rules:      pgtype.Text{String: rules, Valid: true},
+	}
+}
+
+func (c *genreCache) load(ctx context.Context, groups []genreGroup) map[string][]string {
+	cached := make(map[string][]string)
+	if len(groups) == 0 {
+		return cached
+	}
+
+	keys := make([]string, 0, len(groups))
+	for _, group := range groups {
+		keys = append(keys, group.Key)
+	}
+
+	rows, err := c.queries.GetGenreCache(ctx, db.GetGenreCacheParams{
+		GenreKeys:  keys,
+		Rules:      c.rules,
+		FreshSince: c.freshSince,
+	})
+	if err != nil {
+		log.Printf("Failed to read genre cache: %v", err)
+		return cached
+	}
+
+	for _, row := range rows {
+		cached[row.GenreKey] = row.Genres
+	}
+	return cached
+}
+
+func (c *genreCache) store(ctx context.Context, key string, genres []string) {
+	if genres == nil {
+		genres = []string{}
+	}
+	err := c.queries.UpsertGenreCache(ctx, db.UpsertGenreCacheParams{
+		GenreKey: key,
+		Genres:   genres,
+		Rules:    c.rules,
+	})
+	if err != nil {
+		log.Printf("Failed to cache genres for %q: %v", key, err)
+	}
+}
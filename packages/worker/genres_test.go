@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+
+	"last-year-fm/worker/db"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestGroupGenreScrobbles(t *testing.T) {
+	scrobble := func(id byte, artistMbid, releaseGroupMbid, recordingMbid string) db.GetScrobblesForGenreLookupRow {
+		return db.GetScrobblesForGenreLookupRow{
+			ID:                      pgtype.UUID{Bytes: [16]byte{id}, Valid: true},
+			ArtistMbid:              pgtype.Text{String: artistMbid, Valid: artistMbid != ""},
+			MatchedReleaseGroupMbid: pgtype.Text{String: releaseGroupMbid, Valid: releaseGroupMbid != ""},
+			MatchedRecordingMbid:    pgtype.Text{String: recordingMbid, Valid: recordingMbid != ""},
+		}
+	}
+
+	tests := []struct {
+		name              string
+		scrobbles         []db.GetScrobblesForGenreLookupRow
+		expectedGroups    [][]byte
+		expectedUnmatched []byte
+	}{
+		{
+			name: "same match shares a lookup regardless of case",
+			scrobbles: []db.GetScrobblesForGenreLookupRow{
+				scrobble(1, "", "rg-a", "rec-a"),
+				scrobble(2, "", "RG-A", "REC-A"),
+				scrobble(3, "", "rg-a", "rec-b"),
+			},
+			expectedGroups: [][]byte{{1, 2}, {3}},
+		},
+		{
+			name: "artist MBID only counts without a match",
+			scrobbles: []db.GetScrobblesForGenreLookupRow{
+				scrobble(1, "artist-a", "rg-a", ""),
+				scrobble(2, "artist-b", "rg-a", ""),
+				scrobble(3, "artist-a", "", ""),
+			},
+			expectedGroups: [][]byte{{1, 2}, {3}},
+		},
+		{
+			name: "scrobbles without any MBID have nothing to look up",
+			scrobbles: []db.GetScrobblesForGenreLookupRow{
+				scrobble(1, "", "", ""),
+				scrobble(2, "", "", "rec-a"),
+				scrobble(3, " ", "", ""),
+			},
+			expectedGroups:    [][]byte{{2}},
+			expectedUnmatched: []byte{1, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, unmatched := groupGenreScrobbles(tt.scrobbles)
+			if len(groups) != len(tt.expectedGroups) {
+				t.Fatalf("got %d groups, want %d", len(groups), len(tt.expectedGroups))
+			}
+			for i, group := range groups {
+				if len(group.IDs) != len(tt.expectedGroups[i]) {
+					t.Fatalf("group %d has %d scrobbles, want %d", i, len(group.IDs), len(tt.expectedGroups[i]))
+				}
+				for j, id := range group.IDs {
+					if id.Bytes[0] != tt.expectedGroups[i][j] {
+						t.Errorf("group %d scrobble %d = %d, want %d", i, j, id.Bytes[0], tt.expectedGroups[i][j])
+					}
+				}
+			}
+			if len(unmatched) != len(tt.expectedUnmatched) {
+				t.Fatalf("got %d unmatched scrobbles, want %d", len(unmatched), len(tt.expectedUnmatched))
+			}
+			for i, id := range unmatched {
+				if id.Bytes[0] != tt.expectedUnmatched[i] {
+					t.Errorf("unmatched scrobble %d = %d, want %d", i, id.Bytes[0], tt.expectedUnmatched[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGenreConfigRules(t *testing.T) {
+	a := GenreConfig{Limit: 3, Concurrency: 10}
+	b := GenreConfig{Limit: 3, Concurrency: 2}
+	c := GenreConfig{Limit: 5, Concurrency: 10}
+
+	if a.Rules() != b.Rules() {
+		t.Errorf("concurrency changed the rules: %q vs %q", a.Rules(), b.Rules())
+	}
+	if a.Rules() == c.Rules() {
+		t.Errorf("limit didn't change the rules: %q", a.Rules())
+	}
+}
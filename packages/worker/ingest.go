@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"last-year-fm/worker/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// scrobbleCopyColumns are the scrobbles columns populated from Last.fm, in COPY order
+var scrobbleCopyColumns = []string{
+	"username",
+	"trackName",
+	"trackMbid",
+	"artistName",
+	"artistMbid",
+	"albumName",
+	"albumMbid",
+	"scrobbledAt",
+	"scrobbledAtUnix",
+	"year",
+}
+
+// PageIngestResult reports the outcome of loading a single page of scrobbles
+type PageIngestResult struct {
+	Inserted       int
+	AlreadyPresent int
+	Skipped        int
+	Duration       time.Duration
+}
+
+// ingestScrobblesPage bulk-loads a page of Last.fm tracks via COPY into a staging table and
+// merges it into scrobbles. The page and its checkpoint update commit in one transaction.
+// If the bulk load fails, the page is inserted row by row so a row the database rejects is
+// skipped instead of failing the page on every retry.
+func ingestScrobblesPage(
+	ctx context.Context,
+	conn *pgx.Conn,
+	username string,
+	year int,
+	tracks []LastFMTrack,
+	checkpoint db.UpdateImportCheckpointProgressParams,
+) (PageIngestResult, error) {
+	startTime := time.Now()
+
+	rows, skipped := scrobbleRowsFromTracks(username, year, tracks)
+	result := PageIngestResult{Skipped: skipped}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if len(rows) > 0 {
+		inserted, copied, err := copyScrobbles(ctx, tx, rows)
+		if err != nil {
+			if ctx.Err() != nil {
+				return result, fmt.Errorf("failed to copy scrobbles: %w", err)
+			}
+			log.Printf("Bulk load failed, inserting %d scrobbles one at a time: %v", len(rows), err)
+			var rejected int
+			inserted, rejected, err = insertScrobbles(ctx, tx, rows)
+			if err != nil {
+				return result, err
+			}
+			copied = len(rows) - rejected
+			result.Skipped += rejected
+		}
+
+		result.Inserted = inserted
+		result.AlreadyPresent = copied - inserted
+	}
+
+	if err := db.New(tx).UpdateImportCheckpointProgress(ctx, checkpoint); err != nil {
+		return result, fmt.Errorf("failed to update import checkpoint: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return result, fmt.Errorf("failed to commit page: %w", err)
+	}
+
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
+
+// copyScrobbles loads rows through the staging table inside a savepoint, so a failure leaves tx usable.
+// It returns the number of rows inserted and copied; rows already present are copied but not inserted.
+func copyScrobbles(ctx context.Context, tx pgx.Tx, rows []db.InsertScrobbleParams) (int, int, error) {
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create savepoint: %w", err)
+	}
+	defer savepoint.Rollback(ctx)
+
+	queries := db.New(savepoint)
+	if err := queries.CreateScrobbleStagingTable(ctx); err != nil {
+		return 0, 0, fmt.Errorf("failed to create staging table: %w", err)
+	}
+
+	copyRows := make([][]any, len(rows))
+	for i, row := range rows {
+		copyRows[i] = scrobbleCopyRow(row)
+	}
+	copied, err := savepoint.CopyFrom(ctx, pgx.Identifier{"scrobbles_staging"}, scrobbleCopyColumns, pgx.CopyFromRows(copyRows))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to copy scrobbles: %w", err)
+	}
+
+	inserted, err := queries.MergeScrobbleStagingTable(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to merge staged scrobbles: %w", err)
+	}
+
+	if err := savepoint.Commit(ctx); err != nil {
+		return 0, 0, fmt.Errorf("failed to release savepoint: %w", err)
+	}
+	return int(inserted), int(copied), nil
+}
+
+// insertScrobbles inserts rows one at a time, each in its own savepoint. Rows the database rejects
+// are logged and skipped. It returns the number of rows inserted and rejected.
+func insertScrobbles(ctx context.Context, tx pgx.Tx, rows []db.InsertScrobbleParams) (int, int, error) {
+	inserted, rejected := 0, 0
+
+	for _, row := range rows {
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return inserted, rejected, fmt.Errorf("failed to create savepoint: %w", err)
+		}
+
+		n, err := db.New(savepoint).InsertScrobble(ctx, row)
+		if err != nil {
+			savepoint.Rollback(ctx)
+			if ctx.Err() != nil {
+				return inserted, rejected, fmt.Errorf("failed to insert scrobble: %w", err)
+			}
+			log.Printf("Skipping scrobble the database rejected: %s - %s (%s): %v", row.ArtistName, row.TrackName, row.ScrobbledAtUnix, err)
+			rejected++
+			continue
+		}
+
+		if err := savepoint.Commit(ctx); err != nil {
+			return inserted, rejected, fmt.Errorf("failed to release savepoint: %w", err)
+		}
+		inserted += int(n)
+	}
+
+	return inserted, rejected, nil
+}
+
+// scrobbleCopyRow orders a row's values like scrobbleCopyColumns
+func scrobbleCopyRow(row db.InsertScrobbleParams) []any {
+	return []any{
+		row.Username,
+		row.TrackName,
+		row.TrackMbid,
+		row.ArtistName,
+		row.ArtistMbid,
+		row.AlbumName,
+		row.AlbumMbid,
+		row.ScrobbledAt,
+		row.ScrobbledAtUnix,
+		row.Year,
+	}
+}
+
+// scrobbleNameMaxLength is the varchar length of the scrobbles name columns
+const scrobbleNameMaxLength = 512
+
+// mbidPattern is the form the scrobbles MBID check constraints accept
+var mbidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// scrobbleRowsFromTracks converts Last.fm tracks to rows, skipping now playing and undated tracks and
+// tracks the scrobbles table would reject: malformed MBIDs, names that are too long or not valid text
+func scrobbleRowsFromTracks(username string, year int, tracks []LastFMTrack) ([]db.InsertScrobbleParams, int) {
+	rows := make([]db.InsertScrobbleParams, 0, len(tracks))
+	skipped := 0
+
+	for _, track := range tracks {
+		if track.Attr != nil && track.Attr.Nowplaying == "true" {
+			log.Printf("Skipping currently playing track: %s - %s", track.Artist.Text, track.Name)
+			skipped++
+			continue
+		}
+
+		if track.Date == nil {
+			log.Printf("Skipping track without date: %s - %s", track.Artist.Text, track.Name)
+			skipped++
+			continue
+		}
+
+		unixTimestamp, err := strconv.ParseInt(track.Date.Uts, 10, 64)
+		if err != nil {
+			log.Printf("Failed to parse timestamp %s: %v", track.Date.Uts, err)
+			skipped++
+			continue
+		}
+
+		row := db.InsertScrobbleParams{
+			Username:        username,
+			TrackName:       track.Name,
+			TrackMbid:       optionalMbid(track.Mbid),
+			ArtistName:      track.Artist.Text,
+			ArtistMbid:      optionalMbid(track.Artist.Mbid),
+			AlbumName:       pgtype.Text{String: track.Album.Text, Valid: track.Album.Text != ""},
+			AlbumMbid:       optionalMbid(track.Album.Mbid),
+			ScrobbledAt:     pgtype.Timestamptz{Time: time.Unix(unixTimestamp, 0), Valid: true},
+			ScrobbledAtUnix: track.Date.Uts,
+			Year:            int32(year),
+		}
+		if err := validateScrobbleRow(row); err != nil {
+			log.Printf("Skipping invalid track %s - %s: %v", track.Artist.Text, track.Name, err)
+			skipped++
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, skipped
+}
+
+// optionalMbid trims and lowercases a Last.fm MBID, which is NULL when empty
+func optionalMbid(mbid string) pgtype.Text {
+	mbid = strings.ToLower(strings.TrimSpace(mbid))
+	return pgtype.Text{String: mbid, Valid: mbid != ""}
+}
+
+// validateScrobbleRow checks a row against the scrobbles column types and check constraints
+func validateScrobbleRow(row db.InsertScrobbleParams) error {
+	for _, mbid := range []pgtype.Text{row.TrackMbid, row.ArtistMbid, row.AlbumMbid} {
+		if mbid.Valid && !mbidPattern.MatchString(mbid.String) {
+			return fmt.Errorf("malformed MBID %q", mbid.String)
+		}
+	}
+
+	for _, name := range []string{row.TrackName, row.ArtistName, row.AlbumName.String} {
+		if !utf8.ValidString(name) || strings.ContainsRune(name, 0) {
+			return fmt.Errorf("name %q is not valid text", name)
+		}
+		if utf8.RuneCountInString(name) > scrobbleNameMaxLength {
+			return fmt.Errorf("name is longer than %d characters", scrobbleNameMaxLength)
+		}
+	}
+
+	return nil
+}
+
+// rowsPerSecond reports ingestion throughput for logging
+func rowsPerSecond(rows int, duration time.Duration) float64 {
+	if duration <= 0 {
+		return 0
+	}
+	return float64(rows) / duration.Seconds()
+}
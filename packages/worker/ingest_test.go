@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestScrobbleRowsFromTracks(t *testing.T) {
+	page := `[
+		{"name": "Now", "artist": {"#text": "Live"}, "album": {"#text": ""}, "@attr": {"nowplaying": "true"}},
+		{"name": "Dated", "mbid": "", "artist": {"#text": "Artist", "mbid": ""}, "album": {"#text": "Album", "mbid": ""}, "date": {"uts": "1733646660"}},
+		{"name": "Undated", "artist": {"#text": "Artist"}, "album": {"#text": ""}},
+		{"name": "Bad", "artist": {"#text": "Artist"}, "album": {"#text": ""}, "date": {"uts": "not-a-number"}}
+	]`
+
+	var tracks []LastFMTrack
+	if err := json.Unmarshal([]byte(page), &tracks); err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+
+	rows, skipped := scrobbleRowsFromTracks("user", 2024, tracks)
+	if len(rows) != 1 || skipped != 3 {
+		t.Fatalf("got %d rows and %d skipped, want 1 row and 3 skipped", len(rows), skipped)
+	}
+
+	row := rows[0]
+	if len(scrobbleCopyRow(row)) != len(scrobbleCopyColumns) {
+		t.Fatalf("row has %d values, want %d", len(scrobbleCopyRow(row)), len(scrobbleCopyColumns))
+	}
+	if row.TrackName != "Dated" || row.ScrobbledAtUnix != "1733646660" || row.Year != 2024 {
+		t.Errorf("unexpected row values: %+v", row)
+	}
+	if row.TrackMbid.Valid {
+		t.Errorf("empty track MBID should be NULL, got %q", row.TrackMbid.String)
+	}
+	if !row.AlbumName.Valid || row.AlbumName.String != "Album" {
+		t.Errorf("album name = %v, want Album", row.AlbumName)
+	}
+}
+
+func TestScrobbleRowsFromTracksRejectsInvalidRows(t *testing.T) {
+	track := func(name, artist, mbid string) LastFMTrack {
+		var tr LastFMTrack
+		tr.Name = name
+		tr.Mbid = mbid
+		tr.Artist.Text = artist
+		tr.Date = &struct {
+			Uts  string `json:"uts"`
+			Text string `json:"#text"`
+		}{Uts: "1733646660"}
+		return tr
+	}
+
+	tests := []struct {
+		name     string
+		track    LastFMTrack
+		expected bool
+	}{
+		{name: "valid MBID", track: track("Song", "Artist", "5b11f4ce-a62d-471e-81fc-a69a8278c7da"), expected: true},
+		{name: "uppercase MBID is lowercased", track: track("Song", "Artist", "5B11F4CE-A62D-471E-81FC-A69A8278C7DA"), expected: true},
+		{name: "malformed MBID", track: track("Song", "Artist", "not-an-mbid"), expected: false},
+		{name: "name at the column limit", track: track(strings.Repeat("é", scrobbleNameMaxLength), "Artist", ""), expected: true},
+		{name: "name over the column limit", track: track("Song", strings.Repeat("a", scrobbleNameMaxLength+1), ""), expected: false},
+		{name: "NUL byte in name", track: track("So\x00ng", "Artist", ""), expected: false},
+		{name: "invalid UTF-8 in name", track: track("Song\xff", "Artist", ""), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, skipped := scrobbleRowsFromTracks("user", 2024, []LastFMTrack{tt.track})
+			if (len(rows) == 1) != tt.expected || len(rows)+skipped != 1 {
+				t.Fatalf("got %d rows and %d skipped, want accepted=%v", len(rows), skipped, tt.expected)
+			}
+			if tt.expected && rows[0].TrackMbid.Valid && rows[0].TrackMbid.String != strings.ToLower(tt.track.Mbid) {
+				t.Errorf("track MBID = %q, want it lowercased", rows[0].TrackMbid.String)
+			}
+		})
+	}
+}
@@ -0,0 +1,757 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"last-year-fm/worker/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Job kinds
+const (
+	jobKindImport           = "import"
+	jobKindFindReleaseYears = "find_release_years"
+)
+
+// errJobCancelled is the cancellation cause of a job stopped via POST /jobs/{id}/cancel
+var errJobCancelled = errors.New("job cancelled")
+
+// JobQueue runs fetch_jobs rows on a pool of in-process workers.
+// Job statuses are documented on fetch_jobs in packages/db/src/schema.ts.
+type JobQueue struct {
+	pool              *pgxpool.Pool
+	queries           *db.Queries
+	instanceID        string // Recorded as claimedBy on the jobs this process runs
+	concurrency       int
+	pollInterval      time.Duration
+	heartbeatInterval time.Duration
+	staleAfter        time.Duration
+	wake              chan struct{}
+	wg                sync.WaitGroup
+	notifier          JobNotifier
+
+	mu      sync.Mutex
+	running map[pgtype.UUID]context.CancelCauseFunc
+}
+
+var jobQueue *JobQueue
+
+// NewJobQueue creates a queue backed by the app database. A pollInterval of zero disables
+// polling, so only jobs enqueued by this process (or present at startup) are picked up.
+// Running jobs are kept alive with a heartbeat every heartbeatInterval; any process requeues
+// jobs whose heartbeat is older than staleAfter. notifier may be nil when nobody listens for job events.
+func NewJobQueue(
+	pool *pgxpool.Pool,
+	concurrency int,
+	pollInterval, heartbeatInterval, staleAfter time.Duration,
+	notifier JobNotifier,
+) *JobQueue {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = 15 * time.Second
+	}
+	// A couple of missed heartbeats are tolerated before a job counts as abandoned
+	staleAfter = max(staleAfter, 3*heartbeatInterval)
+
+	return &JobQueue{
+		pool:              pool,
+		queries:           db.New(pool),
+		instanceID:        jobQueueInstanceID(),
+		concurrency:       concurrency,
+		pollInterval:      pollInterval,
+		heartbeatInterval: heartbeatInterval,
+		staleAfter:        staleAfter,
+		wake:              make(chan struct{}, concurrency),
+		notifier:          notifier,
+		running:           make(map[pgtype.UUID]context.CancelCauseFunc),
+	}
+}
+
+// Enqueue creates a pending job, or returns the ID of an identical job that is still active
+func (q *JobQueue) Enqueue(ctx context.Context, kind, username string, year int) (pgtype.UUID, error) {
+	activeID, err := q.queries.GetActiveFetchJob(ctx, db.GetActiveFetchJobParams{
+		Kind:     kind,
+		Username: username,
+		Year:     int32(year),
+	})
+	if err == nil {
+		log.Printf("Reusing active %s job %s for user '%s', year %d", kind, activeID, username, year)
+		return activeID, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return pgtype.UUID{}, fmt.Errorf("failed to look up active job: %w", err)
+	}
+
+	// Create/update user first to satisfy foreign key constraint
+	err = q.queries.UpsertUser(ctx, db.UpsertUserParams{
+		Username:  username,
+		AvatarUrl: pgtype.Text{Valid: false}, // NULL for now
+	})
+	if err != nil {
+		return pgtype.UUID{}, fmt.Errorf("failed to upsert user: %w", err)
+	}
+
+	// A concurrent request may have queued the same job since the check above; the unique index
+	// keeps it to one job, and this request reuses it
+	id, err := q.queries.CreateFetchJob(ctx, db.CreateFetchJobParams{
+		Kind:     kind,
+		Username: username,
+		Year:     int32(year),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		activeID, err = q.queries.GetActiveFetchJob(ctx, db.GetActiveFetchJobParams{
+			Kind:     kind,
+			Username: username,
+			Year:     int32(year),
+		})
+		if err != nil {
+			return pgtype.UUID{}, fmt.Errorf("failed to look up conflicting job: %w", err)
+		}
+		log.Printf("Reusing concurrently queued %s job %s for user '%s', year %d", kind, activeID, username, year)
+		return activeID, nil
+	}
+	if err != nil {
+		return pgtype.UUID{}, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	log.Printf("Enqueued %s job %s for user '%s', year %d", kind, id, username, year)
+	q.notify()
+	return id, nil
+}
+
+// jobQueueInstanceID identifies this process in fetch_jobs.claimedBy. The start time keeps it
+// unique when a restarted container reuses the hostname and PID.
+func jobQueueInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "worker"
+	}
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), strconv.FormatInt(time.Now().UnixNano(), 36))
+}
+
+// Start requeues jobs abandoned by dead worker processes and launches the workers.
+// Jobs other processes are still running keep their fresh heartbeat and are left alone.
+func (q *JobQueue) Start(ctx context.Context) error {
+	if _, err := q.requeueStale(ctx); err != nil {
+		return fmt.Errorf("failed to requeue interrupted jobs: %w", err)
+	}
+
+	q.wg.Add(1)
+	go q.heartbeat(ctx)
+
+	for i := 1; i <= q.concurrency; i++ {
+		q.wg.Add(1)
+		go q.worker(ctx, i)
+	}
+
+	log.Printf("Job queue %s started with %d workers", q.instanceID, q.concurrency)
+	return nil
+}
+
+// requeueStale puts running jobs whose heartbeat is older than staleAfter back to pending
+func (q *JobQueue) requeueStale(ctx context.Context) (int64, error) {
+	requeued, err := q.queries.RequeueStaleFetchJobs(ctx, pgtype.Interval{
+		Microseconds: q.staleAfter.Microseconds(),
+		Valid:        true,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if requeued > 0 {
+		log.Printf("Requeued %d jobs without a heartbeat for %v", requeued, q.staleAfter)
+	}
+	return requeued, nil
+}
+
+// heartbeat refreshes heartbeatAt on this process's running jobs. With polling enabled it also
+// requeues jobs abandoned by other processes; without it, nothing touches the database while idle.
+func (q *JobQueue) heartbeat(ctx context.Context) {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.heartbeatInterval)
+	defer ticker.Stop()
+
+	var sweep <-chan time.Time
+	if q.pollInterval > 0 {
+		sweepTicker := time.NewTicker(max(q.pollInterval, q.heartbeatInterval))
+		defer sweepTicker.Stop()
+		sweep = sweepTicker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			q.mu.Lock()
+			running := len(q.running)
+			q.mu.Unlock()
+			if running == 0 {
+				continue
+			}
+			if _, err := q.queries.TouchFetchJobHeartbeats(ctx, pgtype.Text{String: q.instanceID, Valid: true}); err != nil && ctx.Err() == nil {
+				log.Printf("Failed to refresh job heartbeats: %v", err)
+			}
+		case <-sweep:
+			requeued, err := q.requeueStale(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Failed to requeue stale jobs: %v", err)
+			}
+			if requeued > 0 {
+				q.notify()
+			}
+		}
+	}
+}
+
+// Wait blocks until all workers have exited
+func (q *JobQueue) Wait() {
+	q.wg.Wait()
+}
+
+// Cancel stops a job. A pending job is cancelled immediately; a running job is signalled and
+// recorded as cancelled by its worker once the current scrobble or page finishes.
+// It reports whether the job was pending or running; a job that finished first reports false.
+func (q *JobQueue) Cancel(ctx context.Context, id pgtype.UUID) (bool, error) {
+	// Signalling under the lock pairs with finish: the worker either sees the cause or is gone
+	q.mu.Lock()
+	cancel, ok := q.running[id]
+	if ok {
+		cancel(errJobCancelled)
+	}
+	q.mu.Unlock()
+	if ok {
+		return true, nil
+	}
+
+	cancelled, err := q.queries.CancelPendingFetchJob(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel job: %w", err)
+	}
+	if cancelled == 0 {
+		return false, nil
+	}
+
+	log.Printf("Cancelled pending job %s", id)
+	q.publish(ctx, jobEventCancelled, id)
+	return true, nil
+}
+
+// finish stops tracking a running job and reports whether it was cancelled. Cancel can't reach
+// the job afterwards, so a cancel that returned true is always seen here.
+func (q *JobQueue) finish(jobCtx context.Context, id pgtype.UUID) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.running, id)
+	return errors.Is(context.Cause(jobCtx), errJobCancelled)
+}
+
+func (q *JobQueue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *JobQueue) worker(ctx context.Context, workerID int) {
+	defer q.wg.Done()
+
+	var poll <-chan time.Time
+	if q.pollInterval > 0 {
+		ticker := time.NewTicker(q.pollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	for {
+		// Drain every claimable job before going back to sleep
+		for {
+			job, err := q.queries.ClaimNextFetchJob(ctx, pgtype.Text{String: q.instanceID, Valid: true})
+			if errors.Is(err, pgx.ErrNoRows) {
+				break
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Printf("Worker %d: failed to claim job: %v", workerID, err)
+				break
+			}
+
+			q.run(ctx, workerID, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		case <-poll:
+		}
+	}
+}
+
+func (q *JobQueue) run(ctx context.Context, workerID int, job db.ClaimNextFetchJobRow) {
+	log.Printf("Worker %d: running %s job %s for user '%s', year %d", workerID, job.Kind, job.ID, job.Username, job.Year)
+	startTime := time.Now()
+
+	// The job gets its own context so it can be cancelled without stopping the worker
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	q.mu.Lock()
+	q.running[job.ID] = cancel
+	q.mu.Unlock()
+
+	q.publish(ctx, jobEventStarted, job.ID)
+
+	// Progress is written with the worker context so the final counters survive cancellation
+	var err error
+	switch job.Kind {
+	case jobKindImport:
+		var stats ImportStats
+		stats, err = importScrobbles(jobCtx, q.pool, job.Username, int(job.Year), func(stats ImportStats) {
+			q.updateImportProgress(ctx, job.ID, stats)
+			q.publish(ctx, jobEventProgress, job.ID)
+		})
+		if err != nil && stats.PagesFetched > 0 {
+			q.updateImportProgress(ctx, job.ID, stats)
+		}
+	case jobKindFindReleaseYears:
+		if mbPool == nil {
+			err = fmt.Errorf("MusicBrainz database not available")
+			break
+		}
+		phase := ""
+		var stats ReleaseYearStats
+		stats, err = findReleaseYearsForScrobbles(jobCtx, q.queries, job.Username, int(job.Year), func(stats ReleaseYearStats) {
+			q.updateReleaseYearProgress(ctx, job.ID, stats)
+			if stats.Phase != phase {
+				phase = stats.Phase
+				q.publish(ctx, jobEventPhase, job.ID)
+				return
+			}
+			q.publish(ctx, jobEventProgress, job.ID)
+		})
+		if err != nil {
+			if stats.Phase != "" {
+				q.updateReleaseYearProgress(ctx, job.ID, stats)
+			}
+			break
+		}
+
+		// Genres come from the entities the release years were matched to
+		genreStats, genreErr := findGenresForScrobbles(jobCtx, q.queries, job.Username, int(job.Year), func(stats GenreStats) {
+			q.updateGenreProgress(ctx, job.ID, stats)
+			if stats.Phase != phase {
+				phase = stats.Phase
+				q.publish(ctx, jobEventPhase, job.ID)
+				return
+			}
+			q.publish(ctx, jobEventProgress, job.ID)
+		})
+		if genreErr != nil && genreStats.Phase != "" {
+			q.updateGenreProgress(ctx, job.ID, genreStats)
+		}
+
+		// Cancellation and shutdown stop the job as usual. Any other genre failure is recorded
+		// on the side: the release years are in, so the job completes and the next run retries
+		// the scrobbles still without genres.
+		if genreErr != nil && jobCtx.Err() != nil {
+			err = genreErr
+		} else if genreErr != nil {
+			log.Printf("Worker %d: genre lookup for job %s failed: %v", workerID, job.ID, genreErr)
+			setErr := q.queries.SetGenreJobError(ctx, db.SetGenreJobErrorParams{
+				ID:                job.ID,
+				GenreErrorMessage: pgtype.Text{String: genreErr.Error(), Valid: true},
+			})
+			if setErr != nil {
+				log.Printf("Worker %d: failed to record genre error for job %s: %v", workerID, job.ID, setErr)
+			}
+		}
+	default:
+		err = fmt.Errorf("unknown job kind '%s'", job.Kind)
+	}
+
+	cancelRequested := q.finish(jobCtx, job.ID)
+
+	// Jobs interrupted by shutdown go back to the queue; if that write fails, their heartbeat goes
+	// stale and another process requeues them
+	if err != nil && ctx.Err() != nil && !cancelRequested {
+		log.Printf("Worker %d: %s job %s interrupted by shutdown: %v", workerID, job.Kind, job.ID, err)
+		releaseCtx, cancelRelease := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancelRelease()
+		releaseErr := q.queries.ReleaseFetchJob(releaseCtx, db.ReleaseFetchJobParams{
+			ID:        job.ID,
+			ClaimedBy: pgtype.Text{String: q.instanceID, Valid: true},
+		})
+		if releaseErr != nil {
+			log.Printf("Worker %d: failed to requeue job %s: %v", workerID, job.ID, releaseErr)
+		}
+		return
+	}
+
+	// Rows enriched before cancellation stay; a later run picks up the rest. A cancel that arrived
+	// after the work was done still wins, since the client was told it would.
+	if cancelRequested {
+		log.Printf("Worker %d: %s job %s cancelled after %v", workerID, job.Kind, job.ID, time.Since(startTime))
+		if cancelErr := q.queries.CancelFetchJob(ctx, job.ID); cancelErr != nil {
+			log.Printf("Worker %d: failed to mark job %s as cancelled: %v", workerID, job.ID, cancelErr)
+		}
+		q.publish(ctx, jobEventCancelled, job.ID)
+		return
+	}
+
+	if err != nil {
+		log.Printf("Worker %d: %s job %s failed after %v: %v", workerID, job.Kind, job.ID, time.Since(startTime), err)
+		failErr := q.queries.FailFetchJob(ctx, db.FailFetchJobParams{
+			ID:           job.ID,
+			ErrorMessage: pgtype.Text{String: err.Error(), Valid: true},
+		})
+		if failErr != nil {
+			log.Printf("Worker %d: failed to mark job %s as failed: %v", workerID, job.ID, failErr)
+		}
+		q.publish(ctx, jobEventFailed, job.ID)
+		return
+	}
+
+	if err := q.queries.CompleteFetchJob(ctx, job.ID); err != nil {
+		log.Printf("Worker %d: failed to mark job %s as completed: %v", workerID, job.ID, err)
+		return
+	}
+	q.publish(ctx, jobEventCompleted, job.ID)
+
+	log.Printf("Worker %d: %s job %s completed in %v", workerID, job.Kind, job.ID, time.Since(startTime))
+}
+
+// publish sends the job's current state to the notifier, if any
+func (q *JobQueue) publish(ctx context.Context, eventType string, id pgtype.UUID) {
+	if q.notifier == nil {
+		return
+	}
+
+	job, err := q.queries.GetFetchJob(ctx, id)
+	if err != nil {
+		log.Printf("Failed to load job %s for %s event: %v", id, eventType, err)
+		return
+	}
+
+	q.notifier.Notify(JobEvent{
+		Type:   eventType,
+		Job:    newJobView(job),
+		SentAt: time.Now(),
+	})
+}
+
+func (q *JobQueue) updateImportProgress(ctx context.Context, id pgtype.UUID, stats ImportStats) {
+	err := q.queries.UpdateImportJobProgress(ctx, db.UpdateImportJobProgressParams{
+		ID:                      id,
+		Progress:                int32(stats.LastCompletedPage),
+		TotalPages:              pgtype.Int4{Int32: int32(stats.TotalPages), Valid: stats.TotalPages > 0},
+		TotalScrobbles:          pgtype.Int4{Int32: int32(stats.TotalTracks), Valid: stats.TotalTracks > 0},
+		ScrobblesInserted:       int32(stats.Inserted),
+		ScrobblesAlreadyPresent: int32(stats.AlreadyPresent),
+	})
+	if err != nil {
+		log.Printf("Failed to update progress for job %s: %v", id, err)
+	}
+}
+
+func (q *JobQueue) updateReleaseYearProgress(ctx context.Context, id pgtype.UUID, stats ReleaseYearStats) {
+	err := q.queries.UpdateReleaseYearJobProgress(ctx, db.UpdateReleaseYearJobProgressParams{
+		ID:             id,
+		Phase:          pgtype.Text{String: stats.Phase, Valid: stats.Phase != ""},
+		Progress:       int32(stats.Processed),
+		TotalScrobbles: pgtype.Int4{Int32: int32(stats.Total), Valid: true},
+		MbidFound:      int32(stats.MbidFound),
+		MbidRedirected: int32(stats.Redirected),
+		FuzzyFound:     int32(stats.FuzzyFound),
+		NotFound:       int32(stats.NotFound),
+	})
+	if err != nil {
+		log.Printf("Failed to update progress for job %s: %v", id, err)
+	}
+}
+
+func (q *JobQueue) updateGenreProgress(ctx context.Context, id pgtype.UUID, stats GenreStats) {
+	err := q.queries.UpdateGenreJobProgress(ctx, db.UpdateGenreJobProgressParams{
+		ID:              id,
+		Progress:        int32(stats.Processed),
+		TotalScrobbles:  pgtype.Int4{Int32: int32(stats.Total), Valid: true},
+		GenresFound:     int32(stats.Found),
+		GenresNotFound:  int32(stats.NotFound),
+		GenresTimedOut:  int32(stats.TimedOut),
+		GenresErrors:    int32(stats.Errors),
+		GenresCacheHits: int32(stats.CacheHits),
+	})
+	if err != nil {
+		log.Printf("Failed to update progress for job %s: %v", id, err)
+	}
+}
+
+// initAppPool connects to the app database. Every job runs on this pool: an import holds a
+// connection for its whole run and a lookup's workers write concurrently, so it is sized for
+// jobWorkers jobs on top of the HTTP handlers.
+func initAppPool(ctx context.Context, jobWorkers int) (*pgxpool.Pool, error) {
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		return nil, fmt.Errorf("DATABASE_URL environment variable not set")
+	}
+
+	config, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database URL: %w", err)
+	}
+	lookupWorkers := max(releaseYearConfig.MbidConcurrency, releaseYearConfig.FuzzyConcurrency, genreConfig.Concurrency, 1)
+	config.MaxConns = max(config.MaxConns, int32(jobWorkers*lookupWorkers+4))
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create database connection pool: %w", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return pool, nil
+}
+
+// JobView is the public representation of a fetch_jobs row
+type JobView struct {
+	ID                      string     `json:"id"`
+	Kind                    string     `json:"kind"`
+	Username                string     `json:"username"`
+	Year                    int        `json:"year"`
+	Status                  string     `json:"status"`
+	Phase                   string     `json:"phase,omitempty"`
+	PagesFetched            int        `json:"pages_fetched"`
+	TotalPages              *int       `json:"total_pages"`
+	TotalScrobbles          *int       `json:"total_scrobbles"`
+	ScrobblesInserted       int        `json:"scrobbles_inserted"`
+	ScrobblesAlreadyPresent int        `json:"scrobbles_already_present"`
+	Processed               int        `json:"processed"`
+	MbidFound               int        `json:"mbid_found"`
+	MbidRedirected          int        `json:"mbid_redirected"`
+	FuzzyFound              int        `json:"fuzzy_found"`
+	NotFound                int        `json:"not_found"`
+	GenresFound             int        `json:"genres_found"`
+	GenresNotFound          int        `json:"genres_not_found"`
+	GenresTimedOut          int        `json:"genres_timed_out"`
+	GenresErrors            int        `json:"genres_errors"`
+	GenresCacheHits         int        `json:"genres_cache_hits"`
+	Error                   string     `json:"error,omitempty"`
+	GenreError              string     `json:"genre_error,omitempty"`
+	CreatedAt               time.Time  `json:"created_at"`
+	UpdatedAt               time.Time  `json:"updated_at"`
+	StartedAt               *time.Time `json:"started_at"`
+	FinishedAt              *time.Time `json:"finished_at"`
+}
+
+type JobResponse struct {
+	Success bool     `json:"success"`
+	Job     *JobView `json:"job,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+type JobListResponse struct {
+	Success bool      `json:"success"`
+	Jobs    []JobView `json:"jobs"`
+	Error   string    `json:"error,omitempty"`
+}
+
+func newJobView(job db.GetFetchJobRow) JobView {
+	view := JobView{
+		ID:                      job.ID.String(),
+		Kind:                    job.Kind,
+		Username:                job.Username,
+		Year:                    int(job.Year),
+		Status:                  job.Status,
+		Phase:                   job.Phase.String,
+		TotalPages:              optionalInt(job.TotalPages),
+		TotalScrobbles:          optionalInt(job.TotalScrobbles),
+		ScrobblesInserted:       int(job.ScrobblesInserted),
+		ScrobblesAlreadyPresent: int(job.ScrobblesAlreadyPresent),
+		MbidFound:               int(job.MbidFound),
+		MbidRedirected:          int(job.MbidRedirected),
+		FuzzyFound:              int(job.FuzzyFound),
+		NotFound:                int(job.NotFound),
+		GenresFound:             int(job.GenresFound),
+		GenresNotFound:          int(job.GenresNotFound),
+		GenresTimedOut:          int(job.GenresTimedOut),
+		GenresErrors:            int(job.GenresErrors),
+		GenresCacheHits:         int(job.GenresCacheHits),
+		Error:                   job.ErrorMessage.String,
+		GenreError:              job.GenreErrorMessage.String,
+		CreatedAt:               job.CreatedAt.Time,
+		UpdatedAt:               job.UpdatedAt.Time,
+		StartedAt:               optionalTime(job.StartedAt),
+		FinishedAt:              optionalTime(job.FinishedAt),
+	}
+
+	// progress counts pages for imports and scrobbles for release year and genre lookups
+	if job.Kind == jobKindImport {
+		view.PagesFetched = int(job.Progress)
+	} else {
+		view.Processed = int(job.Progress)
+	}
+
+	return view
+}
+
+func optionalInt(value pgtype.Int4) *int {
+	if !value.Valid {
+		return nil
+	}
+	v := int(value.Int32)
+	return &v
+}
+
+func optionalTime(value pgtype.Timestamptz) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
+
+func handleGetJob(w http.ResponseWriter, r *http.Request) {
+	var id pgtype.UUID
+	if err := id.Scan(r.PathValue("id")); err != nil {
+		respondJSON(w, http.StatusBadRequest, JobResponse{
+			Success: false,
+			Error:   "Invalid job ID",
+		})
+		return
+	}
+
+	job, err := jobQueue.queries.GetFetchJob(r.Context(), id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		respondJSON(w, http.StatusNotFound, JobResponse{
+			Success: false,
+			Error:   "Job not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to get job %s: %v", id, err)
+		respondJSON(w, http.StatusInternalServerError, JobResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	view := newJobView(job)
+	respondJSON(w, http.StatusOK, JobResponse{
+		Success: true,
+		Job:     &view,
+	})
+}
+
+func handleListJobs(w http.ResponseWriter, r *http.Request) {
+	params := db.ListFetchJobsParams{Limit: 50}
+
+	if username := r.URL.Query().Get("username"); username != "" {
+		params.Username = pgtype.Text{String: username, Valid: true}
+	}
+
+	if yearParam := r.URL.Query().Get("year"); yearParam != "" {
+		year, err := strconv.Atoi(yearParam)
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, JobListResponse{
+				Success: false,
+				Jobs:    []JobView{},
+				Error:   "Invalid year",
+			})
+			return
+		}
+		params.Year = pgtype.Int4{Int32: int32(year), Valid: true}
+	}
+
+	jobs, err := jobQueue.queries.ListFetchJobs(r.Context(), params)
+	if err != nil {
+		log.Printf("Failed to list jobs: %v", err)
+		respondJSON(w, http.StatusInternalServerError, JobListResponse{
+			Success: false,
+			Jobs:    []JobView{},
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	views := make([]JobView, 0, len(jobs))
+	for _, job := range jobs {
+		views = append(views, newJobView(db.GetFetchJobRow(job)))
+	}
+
+	respondJSON(w, http.StatusOK, JobListResponse{
+		Success: true,
+		Jobs:    views,
+	})
+}
+
+func handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	var id pgtype.UUID
+	if err := id.Scan(r.PathValue("id")); err != nil {
+		respondJSON(w, http.StatusBadRequest, JobResponse{
+			Success: false,
+			Error:   "Invalid job ID",
+		})
+		return
+	}
+
+	cancelled, err := jobQueue.Cancel(r.Context(), id)
+	if err != nil {
+		log.Printf("Failed to cancel job %s: %v", id, err)
+		respondJSON(w, http.StatusInternalServerError, JobResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	job, err := jobQueue.queries.GetFetchJob(r.Context(), id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		respondJSON(w, http.StatusNotFound, JobResponse{
+			Success: false,
+			Error:   "Job not found",
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to get job %s: %v", id, err)
+		respondJSON(w, http.StatusInternalServerError, JobResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	view := newJobView(job)
+	if !cancelled {
+		respondJSON(w, http.StatusConflict, JobResponse{
+			Success: false,
+			Job:     &view,
+			Error:   fmt.Sprintf("Job is %s and can't be cancelled", job.Status),
+		})
+		return
+	}
+
+	// A running job is still winding down; its status changes once the worker stops
+	respondJSON(w, http.StatusAccepted, JobResponse{
+		Success: true,
+		Job:     &view,
+	})
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"last-year-fm/worker/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestNewJobView(t *testing.T) {
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		job                  db.GetFetchJobRow
+		expectedPagesFetched int
+		expectedProcessed    int
+		expectTotalPages     bool
+		expectStartedAt      bool
+		expectedError        string
+		expectedGenreError   string
+	}{
+		{
+			name: "import progress counts pages",
+			job: db.GetFetchJobRow{
+				Kind:       jobKindImport,
+				Status:     "fetching",
+				Progress:   3,
+				TotalPages: pgtype.Int4{Int32: 10, Valid: true},
+				CreatedAt:  pgtype.Timestamptz{Time: createdAt, Valid: true},
+				StartedAt:  pgtype.Timestamptz{Time: createdAt, Valid: true},
+			},
+			expectedPagesFetched: 3,
+			expectTotalPages:     true,
+			expectStartedAt:      true,
+		},
+		{
+			name: "release year progress counts scrobbles",
+			job: db.GetFetchJobRow{
+				Kind:      jobKindFindReleaseYears,
+				Status:    "pending",
+				Progress:  250,
+				CreatedAt: pgtype.Timestamptz{Time: createdAt, Valid: true},
+			},
+			expectedProcessed: 250,
+		},
+		{
+			name: "genre failure is reported apart from the job error",
+			job: db.GetFetchJobRow{
+				Kind:              jobKindFindReleaseYears,
+				Status:            "completed",
+				Progress:          40,
+				GenreErrorMessage: pgtype.Text{String: "genre lookup stopped", Valid: true},
+				CreatedAt:         pgtype.Timestamptz{Time: createdAt, Valid: true},
+			},
+			expectedProcessed:  40,
+			expectedGenreError: "genre lookup stopped",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := newJobView(tt.job)
+			if view.PagesFetched != tt.expectedPagesFetched || view.Processed != tt.expectedProcessed {
+				t.Errorf("got pages_fetched=%d processed=%d, want %d and %d",
+					view.PagesFetched, view.Processed, tt.expectedPagesFetched, tt.expectedProcessed)
+			}
+			if (view.TotalPages != nil) != tt.expectTotalPages {
+				t.Errorf("total_pages = %v, want present=%v", view.TotalPages, tt.expectTotalPages)
+			}
+			if (view.StartedAt != nil) != tt.expectStartedAt {
+				t.Errorf("started_at = %v, want present=%v", view.StartedAt, tt.expectStartedAt)
+			}
+			if view.Error != tt.expectedError || view.GenreError != tt.expectedGenreError {
+				t.Errorf("got error=%q genre_error=%q, want %q and %q",
+					view.Error, view.GenreError, tt.expectedError, tt.expectedGenreError)
+			}
+			if !view.CreatedAt.Equal(createdAt) {
+				t.Errorf("created_at = %v, want %v", view.CreatedAt, createdAt)
+			}
+		})
+	}
+}
+
+func TestNewJobQueueHeartbeatSettings(t *testing.T) {
+	tests := []struct {
+		name              string
+		heartbeatInterval time.Duration
+		staleAfter        time.Duration
+		expectedInterval  time.Duration
+		expectedStale     time.Duration
+	}{
+		{
+			name:              "configured values",
+			heartbeatInterval: 10 * time.Second,
+			staleAfter:        time.Minute,
+			expectedInterval:  10 * time.Second,
+			expectedStale:     time.Minute,
+		},
+		{
+			name:              "timeout tolerates missed heartbeats",
+			heartbeatInterval: 30 * time.Second,
+			staleAfter:        30 * time.Second,
+			expectedInterval:  30 * time.Second,
+			expectedStale:     90 * time.Second,
+		},
+		{
+			name:             "unset interval falls back to the default",
+			expectedInterval: 15 * time.Second,
+			expectedStale:    45 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewJobQueue(nil, 1, 0, tt.heartbeatInterval, tt.staleAfter, nil)
+			if q.heartbeatInterval != tt.expectedInterval || q.staleAfter != tt.expectedStale {
+				t.Errorf("got interval=%v stale=%v, want %v and %v",
+					q.heartbeatInterval, q.staleAfter, tt.expectedInterval, tt.expectedStale)
+			}
+			if q.instanceID == "" {
+				t.Error("instance ID is empty")
+			}
+		})
+	}
+}
+
+// fakeJobDB answers the queries handleCancelJob runs against a single fetch_jobs row
+type fakeJobDB struct {
+	status string
+}
+
+func (f *fakeJobDB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	if strings.Contains(sql, "name: CancelPendingFetchJob") && f.status == "pending" {
+		f.status = "cancelled"
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	}
+	return pgconn.NewCommandTag("UPDATE 0"), nil
+}
+
+func (f *fakeJobDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return nil, errors.New("unexpected query")
+}
+
+func (f *fakeJobDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return fakeJobRow{status: f.status}
+}
+
+// fakeJobRow scans into GetFetchJob's columns, filling in kind and status
+type fakeJobRow struct {
+	status string
+}
+
+func (r fakeJobRow) Scan(dest ...interface{}) error {
+	*dest[1].(*string) = jobKindFindReleaseYears
+	*dest[4].(*string) = r.status
+	return nil
+}
+
+func TestHandleCancelJob(t *testing.T) {
+	const jobID = "0b6f3c1e-8a52-4d6e-9f0a-2c7d1e4b5a69"
+	var id pgtype.UUID
+	if err := id.Scan(jobID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name             string
+		status           string
+		running          bool // Registered with the queue, as while a worker runs it
+		finished         bool // The worker got past its last cancellation check
+		expectedCode     int
+		expectedStatus   string
+		expectCancelSeen bool
+	}{
+		{
+			name:           "pending job is cancelled",
+			status:         "pending",
+			expectedCode:   http.StatusAccepted,
+			expectedStatus: "cancelled",
+		},
+		{
+			name:             "running job is signalled",
+			status:           "augmenting",
+			running:          true,
+			expectedCode:     http.StatusAccepted,
+			expectedStatus:   "augmenting",
+			expectCancelSeen: true,
+		},
+		{
+			name:           "job that finished first conflicts",
+			status:         "completed",
+			running:        true,
+			finished:       true,
+			expectedCode:   http.StatusConflict,
+			expectedStatus: "completed",
+		},
+	}
+
+	previous := jobQueue
+	defer func() { jobQueue = previous }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobQueue = &JobQueue{
+				queries: db.New(&fakeJobDB{status: tt.status}),
+				running: make(map[pgtype.UUID]context.CancelCauseFunc),
+			}
+
+			jobCtx, cancel := context.WithCancelCause(context.Background())
+			defer cancel(nil)
+			if tt.running {
+				jobQueue.running[id] = cancel
+			}
+			if tt.finished && jobQueue.finish(jobCtx, id) {
+				t.Fatal("finish reported a cancel nobody requested")
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/jobs/"+jobID+"/cancel", nil)
+			req.SetPathValue("id", jobID)
+			recorder := httptest.NewRecorder()
+			handleCancelJob(recorder, req)
+
+			if recorder.Code != tt.expectedCode {
+				t.Errorf("got HTTP %d, want %d", recorder.Code, tt.expectedCode)
+			}
+			var resp JobResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("response is not JSON: %v", err)
+			}
+			if resp.Job == nil || resp.Job.Status != tt.expectedStatus {
+				t.Errorf("got job %+v, want status %q", resp.Job, tt.expectedStatus)
+			}
+			if cancelSeen := errors.Is(context.Cause(jobCtx), errJobCancelled); cancelSeen != tt.expectCancelSeen {
+				t.Errorf("worker saw cancel = %v, want %v", cancelSeen, tt.expectCancelSeen)
+			}
+		})
+	}
+}
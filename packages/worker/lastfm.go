@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+const lastFMAPIURL = "https://ws.audioscrobbler.com/2.0/"
+
+// Last.fm error codes that are worth retrying
+const (
+	lastFMErrorOperationFailed   = 8
+	lastFMErrorTemporarilyDown   = 16
+	lastFMErrorRateLimitExceeded = 29
+)
+
+// LastFMConfig holds the tunables for the shared Last.fm client
+type LastFMConfig struct {
+	APIKey            string
+	BaseURL           string
+	RequestsPerSecond float64
+	Burst             int
+	MaxAttempts       int
+	BaseBackoff       time.Duration
+	MaxBackoff        time.Duration
+	RequestTimeout    time.Duration
+}
+
+// LastFMClient is a rate limited Last.fm API client with retry and backoff
+type LastFMClient struct {
+	config     LastFMConfig
+	httpClient *http.Client
+	limiter    *tokenBucket
+}
+
+// LastFMAPIError is an error returned in a Last.fm response body
+type LastFMAPIError struct {
+	Code    int
+	Message string
+}
+
+func (e *LastFMAPIError) Error() string {
+	return fmt.Sprintf("Last.fm API error %d: %s", e.Code, e.Message)
+}
+
+var (
+	lastFMClient     *LastFMClient
+	lastFMClientOnce sync.Once
+	lastFMClientErr  error
+)
+
+// getLastFMClient returns the process-wide Last.fm client, creating it on first use
+func getLastFMClient() (*LastFMClient, error) {
+	lastFMClientOnce.Do(func() {
+		config, err := loadLastFMConfig()
+		if err != nil {
+			lastFMClientErr = err
+			return
+		}
+		lastFMClient = NewLastFMClient(config)
+	})
+	return lastFMClient, lastFMClientErr
+}
+
+func loadLastFMConfig() (LastFMConfig, error) {
+	apiKey := os.Getenv("LAST_FM_APPLICATION_API_KEY")
+	if apiKey == "" {
+		return LastFMConfig{}, fmt.Errorf("LAST_FM_APPLICATION_API_KEY environment variable not set")
+	}
+
+	return LastFMConfig{
+		APIKey:            apiKey,
+		RequestsPerSecond: envFloat("LAST_FM_REQUESTS_PER_SECOND", 4),
+		Burst:             envInt("LAST_FM_BURST", 5),
+		MaxAttempts:       envInt("LAST_FM_MAX_ATTEMPTS", 5),
+		BaseBackoff:       envDuration("LAST_FM_BASE_BACKOFF", 500*time.Millisecond),
+		MaxBackoff:        envDuration("LAST_FM_MAX_BACKOFF", 30*time.Second),
+		RequestTimeout:    envDuration("LAST_FM_REQUEST_TIMEOUT", 15*time.Second),
+	}, nil
+}
+
+func NewLastFMClient(config LastFMConfig) *LastFMClient {
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = lastFMAPIURL
+	}
+
+	return &LastFMClient{
+		config:     config,
+		httpClient: &http.Client{Timeout: config.RequestTimeout},
+		limiter:    newTokenBucket(config.RequestsPerSecond, config.Burst),
+	}
+}
+
+// Call performs a Last.fm API method call and decodes the JSON body into out.
+// Rate limit, temporary and HTTP 5xx failures are retried with exponential backoff.
+func (c *LastFMClient) Call(ctx context.Context, method string, params url.Values, out any) error {
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = values
+	}
+	query.Set("method", method)
+	query.Set("api_key", c.config.APIKey)
+	query.Set("format", "json")
+	requestURL := c.config.BaseURL + "?" + query.Encode()
+
+	var lastErr error
+	for attempt := 1; attempt <= c.config.MaxAttempts; attempt++ {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		retryable, err := c.do(ctx, requestURL, out)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		if !retryable || attempt == c.config.MaxAttempts {
+			break
+		}
+
+		delay := backoffDelay(attempt, c.config.BaseBackoff, c.config.MaxBackoff)
+		log.Printf("Last.fm %s attempt %d/%d failed: %v (retrying in %v)", method, attempt, c.config.MaxAttempts, err, delay)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
+	return lastErr
+}
+
+// do executes a single request and reports whether a failure is retryable
+func (c *LastFMClient) do(ctx context.Context, requestURL string, out any) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to build Last.fm request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		return true, fmt.Errorf("failed to fetch from Last.fm: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return true, fmt.Errorf("Last.fm returned HTTP %d", resp.StatusCode)
+	}
+
+	// Last.fm reports API errors in the body, often alongside a 4xx status
+	var body struct {
+		Error   int    `json:"error"`
+		Message string `json:"message"`
+	}
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return true, fmt.Errorf("failed to parse Last.fm response: %w", err)
+	}
+	if err := json.Unmarshal(raw, &body); err == nil && body.Error != 0 {
+		apiErr := &LastFMAPIError{Code: body.Error, Message: body.Message}
+		return isRetryableLastFMError(body.Error), apiErr
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("Last.fm returned HTTP %d", resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(raw, out); err != nil {
+		return false, fmt.Errorf("failed to parse Last.fm response: %w", err)
+	}
+
+	return false, nil
+}
+
+func isRetryableLastFMError(code int) bool {
+	switch code {
+	case lastFMErrorOperationFailed, lastFMErrorTemporarilyDown, lastFMErrorRateLimitExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoffDelay returns an exponential backoff with full jitter for the given attempt (1-based)
+func backoffDelay(attempt int, base, maxDelay time.Duration) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+
+	delay := base << (attempt - 1)
+	if delay <= 0 || delay > maxDelay {
+		delay = maxDelay
+	}
+
+	return time.Duration(rand.Int64N(int64(delay) + 1))
+}
+
+// asLastFMAPIError unwraps err into a Last.fm API error, if it is one
+func asLastFMAPIError(err error) (*LastFMAPIError, bool) {
+	var apiErr *LastFMAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// tokenBucket is a simple token bucket rate limiter shared by all Last.fm calls
+type tokenBucket struct {
+	mu       sync.Mutex
+	rate     float64
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+func newTokenBucket(ratePerSecond float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:     ratePerSecond,
+		capacity: float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// Wait blocks until a token is available or ctx is done
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	for {
+		wait := b.reserve()
+		if wait == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// reserve takes a token if one is available, otherwise returns how long to wait for one
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.rate <= 0 {
+		return 0
+	}
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
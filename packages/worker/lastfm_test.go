@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestIsRetryableLastFMError(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     int
+		expected bool
+	}{
+		{
+			name:     "operation failed is retryable",
+			code:     8,
+			expected: true,
+		},
+		{
+			name:     "temporarily unavailable is retryable",
+			code:     16,
+			expected: true,
+		},
+		{
+			name:     "rate limit exceeded is retryable",
+			code:     29,
+			expected: true,
+		},
+		{
+			name:     "invalid parameters is not retryable",
+			code:     6,
+			expected: false,
+		},
+		{
+			name:     "invalid API key is not retryable",
+			code:     10,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := isRetryableLastFMError(tt.code)
+			if result != tt.expected {
+				t.Errorf("isRetryableLastFMError(%d) = %v, want %v", tt.code, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempt  int
+		base     time.Duration
+		maxDelay time.Duration
+		ceiling  time.Duration
+	}{
+		{
+			name:     "first attempt stays within base",
+			attempt:  1,
+			base:     100 * time.Millisecond,
+			maxDelay: time.Second,
+			ceiling:  100 * time.Millisecond,
+		},
+		{
+			name:     "doubles per attempt",
+			attempt:  3,
+			base:     100 * time.Millisecond,
+			maxDelay: time.Second,
+			ceiling:  400 * time.Millisecond,
+		},
+		{
+			name:     "caps at max delay",
+			attempt:  10,
+			base:     100 * time.Millisecond,
+			maxDelay: time.Second,
+			ceiling:  time.Second,
+		},
+		{
+			name:     "zero base disables backoff",
+			attempt:  5,
+			base:     0,
+			maxDelay: time.Second,
+			ceiling:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 50 {
+				result := backoffDelay(tt.attempt, tt.base, tt.maxDelay)
+				if result < 0 || result > tt.ceiling {
+					t.Fatalf("backoffDelay(%d, %v, %v) = %v, want between 0 and %v",
+						tt.attempt, tt.base, tt.maxDelay, result, tt.ceiling)
+				}
+			}
+		})
+	}
+}
+
+func TestLastFMClientRetriesRetryableErrors(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			fmt.Fprint(w, `{"error": 29, "message": "Rate Limit Exceeded"}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok": true}`)
+	}))
+	defer server.Close()
+
+	client := NewLastFMClient(LastFMConfig{
+		APIKey:         "test",
+		BaseURL:        server.URL,
+		MaxAttempts:    3,
+		BaseBackoff:    time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		RequestTimeout: time.Second,
+	})
+
+	var out struct {
+		Ok bool `json:"ok"`
+	}
+	if err := client.Call(context.Background(), "user.getrecenttracks", url.Values{}, &out); err != nil {
+		t.Fatalf("Call returned error: %v", err)
+	}
+	if !out.Ok || attempts != 3 {
+		t.Errorf("got ok=%v after %d attempts, want ok=true after 3 attempts", out.Ok, attempts)
+	}
+}
+
+func TestLastFMClientDoesNotRetryPermanentErrors(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error": 6, "message": "User not found"}`)
+	}))
+	defer server.Close()
+
+	client := NewLastFMClient(LastFMConfig{
+		APIKey:         "test",
+		BaseURL:        server.URL,
+		MaxAttempts:    5,
+		BaseBackoff:    time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		RequestTimeout: time.Second,
+	})
+
+	err := client.Call(context.Background(), "user.getrecenttracks", url.Values{}, &struct{}{})
+	apiErr, ok := asLastFMAPIError(err)
+	if !ok || apiErr.Code != 6 {
+		t.Fatalf("Call error = %v, want Last.fm API error 6", err)
+	}
+	if attempts != 1 {
+		t.Errorf("got %d attempts, want 1", attempts)
+	}
+}
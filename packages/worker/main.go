@@ -3,13 +3,19 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"last-year-fm/worker/db"
@@ -26,10 +32,28 @@ type ImportRequest struct {
 }
 
 type ImportResponse struct {
-	Success        bool   `json:"success"`
-	Message        string `json:"message"`
-	ScrobblesCount int    `json:"scrobbles_count,omitempty"`
-	Error          string `json:"error,omitempty"`
+	Success         bool   `json:"success"`
+	Message         string `json:"message"`
+	ScrobblesCount  int    `json:"scrobbles_count,omitempty"`
+	TotalPages      int    `json:"total_pages,omitempty"`
+	TotalTracks     int    `json:"total_tracks,omitempty"`
+	AlreadyPresent  int    `json:"already_present,omitempty"`
+	Skipped         int    `json:"skipped,omitempty"`
+	ResumedFromPage int    `json:"resumed_from_page,omitempty"`
+	JobID           string `json:"job_id,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+// ImportStats summarizes a single import run across all fetched pages
+type ImportStats struct {
+	Inserted          int
+	AlreadyPresent    int
+	Skipped           int
+	PagesFetched      int
+	TotalPages        int
+	TotalTracks       int
+	ResumedFromPage   int
+	LastCompletedPage int
 }
 
 type FindReleaseYearsRequest struct {
@@ -45,6 +69,7 @@ type FindReleaseYearsResponse struct {
 	MbidFound  int    `json:"mbid_found,omitempty"`
 	FuzzyFound int    `json:"fuzzy_found,omitempty"`
 	NotFound   int    `json:"not_found,omitempty"`
+	JobID      string `json:"job_id,omitempty"`
 	Error      string `json:"error,omitempty"`
 }
 
@@ -55,27 +80,35 @@ type LastFMError struct {
 	Message string `json:"message"`
 }
 
+type LastFMTrack struct {
+	Name   string `json:"name"`
+	Artist struct {
+		Mbid string `json:"mbid"`
+		Text string `json:"#text"`
+	} `json:"artist"`
+	Album struct {
+		Mbid string `json:"mbid"`
+		Text string `json:"#text"`
+	} `json:"album"`
+	Mbid string `json:"mbid"`
+	Date *struct {
+		Uts  string `json:"uts"`
+		Text string `json:"#text"`
+	} `json:"date"`
+	Attr *struct {
+		Nowplaying string `json:"nowplaying"`
+	} `json:"@attr"`
+}
+
 type LastFMResponse struct {
 	RecentTracks *struct {
-		Track []struct {
-			Name   string `json:"name"`
-			Artist struct {
-				Mbid string `json:"mbid"`
-				Text string `json:"#text"`
-			} `json:"artist"`
-			Album struct {
-				Mbid string `json:"mbid"`
-				Text string `json:"#text"`
-			} `json:"album"`
-			Mbid string `json:"mbid"`
-			Date *struct {
-				Uts  string `json:"uts"`
-				Text string `json:"#text"`
-			} `json:"date"`
-			Attr *struct {
-				Nowplaying string `json:"nowplaying"`
-			} `json:"@attr"`
-		} `json:"track"`
+		Track []LastFMTrack `json:"track"`
+		Attr  struct {
+			Page       string `json:"page"`
+			PerPage    string `json:"perPage"`
+			TotalPages string `json:"totalPages"`
+			Total      string `json:"total"`
+		} `json:"@attr"`
 	} `json:"recenttracks"`
 	Error   int    `json:"error"`
 	Message string `json:"message"`
@@ -84,8 +117,38 @@ type LastFMResponse struct {
 func main() {
 	loadEnv()
 
-	// Initialize MusicBrainz connection pool
-	var err error
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Lookup settings size both database pools, so load them first
+	releaseYearConfig = loadReleaseYearConfig()
+	genreConfig = loadGenreConfig()
+
+	// Initialize app database pool and job queue
+	jobWorkers := envInt("JOB_WORKERS", 2)
+	appPool, err := initAppPool(ctx, jobWorkers)
+	if err != nil {
+		log.Fatalf("Failed to initialize database pool: %v", err)
+	}
+	defer appPool.Close()
+
+	// Job events stream over SSE from GET /jobs/{id}/events
+	jobEventBroker = NewJobEventBroker(envInt("JOB_EVENTS_HISTORY", 100), envDuration("JOB_EVENTS_RETENTION", 5*time.Minute))
+	notifiers := JobNotifiers{jobEventBroker}
+
+	// Optional webhook for job progress, see POST /internal/job-update in research/ARCHITECTURE.md
+	webhookConfig, ok, err := loadWebhookConfig()
+	if err != nil {
+		log.Fatalf("Invalid webhook configuration: %v", err)
+	}
+	if ok {
+		webhookNotifier := NewWebhookNotifier(ctx, webhookConfig)
+		defer webhookNotifier.Close()
+		notifiers = append(notifiers, webhookNotifier)
+		log.Printf("Job webhook notifications enabled for %s", webhookConfig.URL)
+	}
+
+	// Initialize MusicBrainz connection pool before the queue starts, jobs present at startup may need it
 	mbPool, err = initMusicBrainzPool()
 	if err != nil {
 		log.Printf("Warning: Failed to initialize MusicBrainz pool: %v", err)
@@ -95,6 +158,18 @@ func main() {
 		log.Printf("MusicBrainz connection pool initialized")
 	}
 
+	jobQueue = NewJobQueue(
+		appPool,
+		jobWorkers,
+		envDuration("JOB_POLL_INTERVAL", 0),
+		envDuration("JOB_HEARTBEAT_INTERVAL", 15*time.Second),
+		envDuration("JOB_HEARTBEAT_TIMEOUT", time.Minute),
+		notifiers,
+	)
+	if err := jobQueue.Start(ctx); err != nil {
+		log.Fatalf("Failed to start job queue: %v", err)
+	}
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
@@ -102,11 +177,33 @@ func main() {
 
 	http.HandleFunc("/import", handleImport)
 	http.HandleFunc("/find-release-years", handleFindReleaseYears)
+	http.HandleFunc("GET /jobs", handleListJobs)
+	http.HandleFunc("GET /jobs/{id}", handleGetJob)
+	http.HandleFunc("GET /jobs/{id}/events", handleJobEvents)
+	http.HandleFunc("POST /jobs/{id}/cancel", handleCancelJob)
+
+	// Request contexts derive from ctx so open SSE streams end on shutdown
+	server := &http.Server{
+		Addr:        ":" + port,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		log.Printf("Shutting down worker server")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Server shutdown error: %v", err)
+		}
+	}()
 
 	log.Printf("Worker server starting on port %s", port)
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatal(err)
 	}
+
+	// Running jobs stop at their next cancellation check and hand themselves back to the queue
+	jobQueue.Wait()
 }
 
 func loadEnv() {
@@ -133,6 +230,63 @@ func loadEnv() {
 	}
 }
 
+func envInt(key string, fallback int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+		log.Printf("Ignoring invalid %s=%q, using %d", key, value, fallback)
+	}
+	return fallback
+}
+
+func envFloat(key string, fallback float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+		log.Printf("Ignoring invalid %s=%q, using %v", key, value, fallback)
+	}
+	return fallback
+}
+
+func envBool(key string, fallback bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+		log.Printf("Ignoring invalid %s=%q, using %v", key, value, fallback)
+	}
+	return fallback
+}
+
+// envList reads a comma-separated list, trimming spaces and dropping empty items.
+// An empty but set variable yields an empty list.
+func envList(key string, fallback []string) []string {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+		log.Printf("Ignoring invalid %s=%q, using %v", key, value, fallback)
+	}
+	return fallback
+}
+
 func handleImport(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		respondJSON(w, http.StatusMethodNotAllowed, ImportResponse{
@@ -168,10 +322,10 @@ func handleImport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Fetch and import scrobbles
-	count, err := importScrobbles(r.Context(), req.Username, req.Year)
+	// Queue the import; a background worker fetches and imports the scrobbles
+	jobID, err := jobQueue.Enqueue(r.Context(), jobKindImport, req.Username, req.Year)
 	if err != nil {
-		log.Printf("Import error for user %s, year %d: %v", req.Username, req.Year, err)
+		log.Printf("Failed to enqueue import for user %s, year %d: %v", req.Username, req.Year, err)
 		respondJSON(w, http.StatusInternalServerError, ImportResponse{
 			Success: false,
 			Error:   err.Error(),
@@ -179,49 +333,36 @@ func handleImport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	message := fmt.Sprintf("Imported %d scrobbles for %s in %d", count, req.Username, req.Year)
-	respondJSON(w, http.StatusOK, ImportResponse{
-		Success:        true,
-		Message:        message,
-		ScrobblesCount: count,
+	respondJSON(w, http.StatusAccepted, ImportResponse{
+		Success: true,
+		Message: fmt.Sprintf("Queued import of scrobbles for %s in %d", req.Username, req.Year),
+		JobID:   jobID.String(),
 	})
 }
 
-func importScrobbles(ctx context.Context, username string, year int) (int, error) {
+// importScrobbles imports a user's scrobbles for a year on a connection from pool, calling onPage
+// after each ingested page
+func importScrobbles(ctx context.Context, pool *pgxpool.Pool, username string, year int, onPage func(ImportStats)) (ImportStats, error) {
 	log.Printf("Starting import for user '%s', year %d", username, year)
 
-	apiKey := os.Getenv("LAST_FM_APPLICATION_API_KEY")
-	if apiKey == "" {
-		return 0, fmt.Errorf("LAST_FM_APPLICATION_API_KEY environment variable not set")
-	}
-
-	startTime := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
-	endTime := time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC)
+	var stats ImportStats
 
-	lfmResp, err := fetchLastFMScrobbles(apiKey, username, startTime.Unix(), endTime.Unix())
+	client, err := getLastFMClient()
 	if err != nil {
-		return 0, err
-	}
-
-	if lfmResp.RecentTracks == nil || len(lfmResp.RecentTracks.Track) == 0 {
-		log.Printf("No scrobbles found for user '%s' in year %d", username, year)
-		return 0, nil
+		return stats, err
 	}
 
-	log.Printf("Fetched %d tracks from Last.fm API", len(lfmResp.RecentTracks.Track))
-
-	// Connect to database
-	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-		return 0, fmt.Errorf("DATABASE_URL environment variable not set")
-	}
+	startTime := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC)
 
-	conn, err := pgx.Connect(ctx, dbURL)
+	// Pages are ingested in transactions with COPY, so the import holds one connection throughout
+	poolConn, err := pool.Acquire(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("failed to connect to database: %w", err)
+		return stats, fmt.Errorf("failed to acquire database connection: %w", err)
 	}
-	defer conn.Close(ctx)
+	defer poolConn.Release()
 
+	conn := poolConn.Conn()
 	queries := db.New(conn)
 
 	// Create/update user first to satisfy foreign key constraint
@@ -230,96 +371,142 @@ func importScrobbles(ctx context.Context, username string, year int) (int, error
 		AvatarUrl: pgtype.Text{Valid: false}, // NULL for now
 	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to upsert user: %w", err)
+		return stats, fmt.Errorf("failed to upsert user: %w", err)
+	}
+
+	// Resume from the last completed page of an unfinished import, otherwise start a fresh checkpoint
+	startPage := 1
+	windowEnd := min(endTime.Unix(), time.Now().Unix())
+	checkpointParams := db.GetImportCheckpointParams{Username: username, Year: int32(year)}
+	checkpoint, err := queries.GetImportCheckpoint(ctx, checkpointParams)
+	switch {
+	case err == nil && !checkpoint.Completed:
+		startPage = int(checkpoint.LastCompletedPage) + 1
+		windowEnd = checkpoint.WindowEndUnix
+		stats.ResumedFromPage = startPage
+		if checkpoint.TotalPages.Valid {
+			stats.TotalPages = int(checkpoint.TotalPages.Int32)
+		}
+		log.Printf("Resuming import for user '%s', year %d from page %d (last scrobble %s)",
+			username, year, startPage, checkpoint.LastScrobbledAtUnix.String)
+	case err == nil || errors.Is(err, pgx.ErrNoRows):
+		err = queries.StartImportCheckpoint(ctx, db.StartImportCheckpointParams{
+			Username:      username,
+			Year:          int32(year),
+			WindowEndUnix: windowEnd,
+		})
+		if err != nil {
+			return stats, fmt.Errorf("failed to start import checkpoint: %w", err)
+		}
+	default:
+		return stats, fmt.Errorf("failed to load import checkpoint: %w", err)
 	}
 
-	count := 0
-	skipped := 0
-	totalTracks := len(lfmResp.RecentTracks.Track)
+	// Walk every page of the from/to window, ingesting each page before fetching the next
+	var ingestDuration time.Duration
+	for page := startPage; stats.TotalPages == 0 || page <= stats.TotalPages; page++ {
+		lfmResp, err := fetchLastFMScrobbles(ctx, client, username, startTime.Unix(), windowEnd, page)
+		if err != nil {
+			return stats, fmt.Errorf("failed to fetch page %d: %w", page, err)
+		}
+
+		if lfmResp.RecentTracks == nil {
+			break
+		}
 
-	for _, track := range lfmResp.RecentTracks.Track {
-		if track.Attr != nil && track.Attr.Nowplaying == "true" {
-			log.Printf("Skipping currently playing track: %s - %s", track.Artist.Text, track.Name)
-			skipped++
-			continue
+		if page == startPage {
+			stats.TotalPages, _ = strconv.Atoi(lfmResp.RecentTracks.Attr.TotalPages)
+			stats.TotalTracks, _ = strconv.Atoi(lfmResp.RecentTracks.Attr.Total)
+			log.Printf("Last.fm reports %d tracks across %d pages for user '%s' in year %d",
+				stats.TotalTracks, stats.TotalPages, username, year)
 		}
 
-		if track.Date == nil {
-			log.Printf("Skipping track without date: %s - %s", track.Artist.Text, track.Name)
-			skipped++
-			continue
+		tracks := lfmResp.RecentTracks.Track
+		if len(tracks) == 0 {
+			break
 		}
 
-		unixTimestamp, err := strconv.ParseInt(track.Date.Uts, 10, 64)
-		if err != nil {
-			log.Printf("Failed to parse timestamp %s: %v", track.Date.Uts, err)
-			skipped++
-			continue
-		}
-
-		scrobbledAt := time.Unix(unixTimestamp, 0)
-
-		trackMbid := pgtype.Text{String: track.Mbid, Valid: track.Mbid != ""}
-		artistMbid := pgtype.Text{String: track.Artist.Mbid, Valid: track.Artist.Mbid != ""}
-		albumName := pgtype.Text{String: track.Album.Text, Valid: track.Album.Text != ""}
-		albumMbid := pgtype.Text{String: track.Album.Mbid, Valid: track.Album.Mbid != ""}
-
-		err = queries.InsertScrobble(ctx, db.InsertScrobbleParams{
-			Username:        username,
-			TrackName:       track.Name,
-			TrackMbid:       trackMbid,
-			ArtistName:      track.Artist.Text,
-			ArtistMbid:      artistMbid,
-			AlbumName:       albumName,
-			AlbumMbid:       albumMbid,
-			ScrobbledAt:     pgtype.Timestamptz{Time: scrobbledAt, Valid: true},
-			ScrobbledAtUnix: track.Date.Uts,
-			Year:            int32(year),
+		result, err := ingestScrobblesPage(ctx, conn, username, year, tracks, db.UpdateImportCheckpointProgressParams{
+			Username:            username,
+			Year:                int32(year),
+			LastCompletedPage:   int32(page),
+			TotalPages:          pgtype.Int4{Int32: int32(stats.TotalPages), Valid: stats.TotalPages > 0},
+			LastScrobbledAtUnix: oldestScrobbleTimestamp(tracks),
 		})
 		if err != nil {
-			log.Printf("Failed to insert scrobble: %v", err)
-			skipped++
-			continue
+			return stats, fmt.Errorf("failed to ingest page %d: %w", page, err)
 		}
 
-		count++
-	}
+		stats.Inserted += result.Inserted
+		stats.AlreadyPresent += result.AlreadyPresent
+		stats.Skipped += result.Skipped
+		stats.PagesFetched++
+		stats.LastCompletedPage = page
+		ingestDuration += result.Duration
 
-	log.Printf("Import complete for user '%s', year %d: inserted %d/%d tracks (%d skipped)", username, year, count, totalTracks, skipped)
-	return count, nil
-}
+		log.Printf("Page %d/%d: inserted %d tracks (%d already present, %d skipped) in %v (%.0f rows/s)",
+			page, stats.TotalPages, result.Inserted, result.AlreadyPresent, result.Skipped,
+			result.Duration, rowsPerSecond(len(tracks)-result.Skipped, result.Duration))
 
-func fetchLastFMScrobbles(apiKey, username string, from, to int64) (*LastFMResponse, error) {
-	url := fmt.Sprintf(
-		"https://ws.audioscrobbler.com/2.0/?method=user.getrecenttracks&user=%s&api_key=%s&from=%d&to=%d&limit=200&format=json",
-		username, apiKey, from, to,
-	)
+		if onPage != nil {
+			onPage(stats)
+		}
+	}
 
-	resp, err := http.Get(url)
+	err = queries.CompleteImportCheckpoint(ctx, db.CompleteImportCheckpointParams{
+		Username: username,
+		Year:     int32(year),
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch from Last.fm: %w", err)
+		return stats, fmt.Errorf("failed to complete import checkpoint: %w", err)
+	}
+
+	if stats.PagesFetched == 0 {
+		log.Printf("No scrobbles found for user '%s' in year %d", username, year)
+		return stats, nil
 	}
-	defer resp.Body.Close()
+
+	log.Printf("Import complete for user '%s', year %d: inserted %d/%d tracks across %d pages (%d already present, %d skipped)",
+		username, year, stats.Inserted, stats.TotalTracks, stats.PagesFetched, stats.AlreadyPresent, stats.Skipped)
+	log.Printf("Ingest throughput: %d rows in %v (%.0f rows/s)",
+		stats.Inserted+stats.AlreadyPresent, ingestDuration, rowsPerSecond(stats.Inserted+stats.AlreadyPresent, ingestDuration))
+	return stats, nil
+}
+
+// oldestScrobbleTimestamp returns the UTS of the oldest dated track on a page (pages are newest first)
+func oldestScrobbleTimestamp(tracks []LastFMTrack) pgtype.Text {
+	for i := len(tracks) - 1; i >= 0; i-- {
+		if tracks[i].Date != nil {
+			return pgtype.Text{String: tracks[i].Date.Uts, Valid: true}
+		}
+	}
+	return pgtype.Text{Valid: false}
+}
+
+func fetchLastFMScrobbles(ctx context.Context, client *LastFMClient, username string, from, to int64, page int) (*LastFMResponse, error) {
+	params := url.Values{}
+	params.Set("user", username)
+	params.Set("from", strconv.FormatInt(from, 10))
+	params.Set("to", strconv.FormatInt(to, 10))
+	params.Set("limit", "200")
+	params.Set("page", strconv.Itoa(page))
 
 	var lfmResp LastFMResponse
-	if err := json.NewDecoder(resp.Body).Decode(&lfmResp); err != nil {
-		return nil, fmt.Errorf("failed to parse Last.fm response: %w", err)
-	}
-
-	// Check for Last.fm API errors
-	if lfmResp.Error != 0 {
-		switch lfmResp.Error {
-		case 6:
-			return nil, fmt.Errorf("user '%s' not found or invalid parameters", username)
-		case 10:
-			return nil, fmt.Errorf("invalid Last.fm API key")
-		case 29:
-			return nil, fmt.Errorf("rate limit exceeded. Please try again later")
-		case 17:
-			return nil, fmt.Errorf("user '%s' has a private profile", username)
-		default:
-			return nil, fmt.Errorf("Last.fm API error %d: %s", lfmResp.Error, lfmResp.Message)
+	if err := client.Call(ctx, "user.getrecenttracks", params, &lfmResp); err != nil {
+		// Map well-known Last.fm API errors to friendlier messages
+		if apiErr, ok := asLastFMAPIError(err); ok {
+			switch apiErr.Code {
+			case 6:
+				return nil, fmt.Errorf("user '%s' not found or invalid parameters", username)
+			case 10:
+				return nil, fmt.Errorf("invalid Last.fm API key")
+			case lastFMErrorRateLimitExceeded:
+				return nil, fmt.Errorf("rate limit exceeded. Please try again later")
+			case 17:
+				return nil, fmt.Errorf("user '%s' has a private profile", username)
+			}
 		}
+		return nil, err
 	}
 
 	return &lfmResp, nil
@@ -372,6 +559,15 @@ func initMusicBrainzPool() (*pgxpool.Pool, error) {
 		return nil, fmt.Errorf("failed to parse MusicBrainz connection string: %w", err)
 	}
 
+	// Enough connections for the widest of the lookup passes
+	config.MaxConns = int32(max(releaseYearConfig.MbidConcurrency, releaseYearConfig.FuzzyConcurrency, genreConfig.Concurrency, 4))
+
+	// The pg_trgm % operator filters on this setting, so fuzzy searches can use the trigram indexes
+	config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		_, err := conn.Exec(ctx, fmt.Sprintf("SET pg_trgm.similarity_threshold = %g", releaseYearConfig.FuzzyThreshold))
+		return err
+	}
+
 	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create MusicBrainz connection pool: %w", err)
@@ -429,9 +625,10 @@ func handleFindReleaseYears(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	processed, mbidFound, fuzzyFound, notFound, err := findReleaseYearsForScrobbles(r.Context(), req.Username, req.Year)
+	// Queue the lookup; a background worker resolves the release years
+	jobID, err := jobQueue.Enqueue(r.Context(), jobKindFindReleaseYears, req.Username, req.Year)
 	if err != nil {
-		log.Printf("Find release years error for user %s, year %d: %v", req.Username, req.Year, err)
+		log.Printf("Failed to enqueue release year lookup for user %s, year %d: %v", req.Username, req.Year, err)
 		respondJSON(w, http.StatusInternalServerError, FindReleaseYearsResponse{
 			Success: false,
 			Error:   err.Error(),
@@ -439,190 +636,150 @@ func handleFindReleaseYears(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	totalFound := mbidFound + fuzzyFound
-	message := fmt.Sprintf("Processed %d scrobbles for %s in %d: %d via MBID, %d via fuzzy, %d not found",
-		processed, req.Username, req.Year, mbidFound, fuzzyFound, notFound)
-	respondJSON(w, http.StatusOK, FindReleaseYearsResponse{
-		Success:    true,
-		Message:    message,
-		Processed:  processed,
-		Found:      totalFound,
-		MbidFound:  mbidFound,
-		FuzzyFound: fuzzyFound,
-		NotFound:   notFound,
+	respondJSON(w, http.StatusAccepted, FindReleaseYearsResponse{
+		Success: true,
+		Message: fmt.Sprintf("Queued release year lookup for %s in %d", req.Username, req.Year),
+		JobID:   jobID.String(),
 	})
 }
 
-func findReleaseYearsForScrobbles(ctx context.Context, username string, year int) (int, int, int, int, error) {
-	log.Printf("Starting release year lookup for user '%s', year %d", username, year)
-
-	// Connect to local database
-	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-		return 0, 0, 0, 0, fmt.Errorf("DATABASE_URL environment variable not set")
-	}
-
-	conn, err := pgx.Connect(ctx, dbURL)
-	if err != nil {
-		return 0, 0, 0, 0, fmt.Errorf("failed to connect to database: %w", err)
-	}
-	defer conn.Close(ctx)
-
-	queries := db.New(conn)
-
-	// Get scrobbles that need release year lookup
-	scrobbles, err := queries.GetScrobblesForReleaseYearLookup(ctx, db.GetScrobblesForReleaseYearLookupParams{
-		Username: username,
-		Year:     int32(year),
-	})
-	if err != nil {
-		return 0, 0, 0, 0, fmt.Errorf("failed to get scrobbles: %w", err)
-	}
-
-	log.Printf("Found %d scrobbles to process", len(scrobbles))
-
-	processed := 0
-	mbidFound := 0
-	fuzzyFound := 0
-	notFound := 0
-
-	// Track which scrobbles were processed in Pass 1
-	processedInPass1 := make(map[pgtype.UUID]bool)
-
-	// Pass 1: Process scrobbles with MBIDs (fast direct lookups)
-	log.Printf("Pass 1: Processing scrobbles with MBIDs (album or track)...")
-	mbidStartTime := time.Now()
-	for _, scrobble := range scrobbles {
-		var year *int
-		var err error
-
-		// Try album MBID first
-		if scrobble.AlbumMbid.Valid && scrobble.AlbumMbid.String != "" {
-			year, err = findReleaseYearByAlbumMbid(ctx, scrobble.AlbumMbid.String)
-		}
-
-		// If no album MBID or lookup failed, try track MBID
-		if (year == nil || err != nil) && scrobble.TrackMbid.Valid && scrobble.TrackMbid.String != "" {
-			year, err = findReleaseYearByTrackMbid(ctx, scrobble.TrackMbid.String)
-		}
-
-		// If we found a year via any MBID, update the scrobble
-		if err == nil && year != nil {
-			releaseYear := pgtype.Int4{Int32: int32(*year), Valid: true}
-			err = queries.UpdateScrobbleReleaseYear(ctx, db.UpdateScrobbleReleaseYearParams{
-				ID:          scrobble.ID,
-				ReleaseYear: releaseYear,
-			})
-			if err != nil {
-				log.Printf("Failed to update scrobble %v: %v", scrobble.ID, err)
-				continue
-			}
-			mbidFound++
-			processed++
-			processedInPass1[scrobble.ID] = true
-		}
-	}
-	log.Printf("Pass 1 complete: %d found via MBID (took %v)", mbidFound, time.Since(mbidStartTime))
-
-	// Pass 2: Process remaining scrobbles with fuzzy search
-	log.Printf("Pass 2: Processing scrobbles with fuzzy search...")
-	fuzzyStartTime := time.Now()
-	for _, scrobble := range scrobbles {
-		// Skip if already processed via MBID in Pass 1
-		if processedInPass1[scrobble.ID] {
-			continue
-		}
-
-		log.Printf("Processing scrobble: %v", scrobble.ID)
-
-		year, err := findReleaseYearByArtistAndTrack(ctx, scrobble.ArtistName, scrobble.TrackName)
-		var releaseYear pgtype.Int4
-		if err == nil && year != nil {
-			releaseYear = pgtype.Int4{Int32: int32(*year), Valid: true}
-			fuzzyFound++
-		} else {
-			notFound++
-		}
-
-		// Update scrobble with release year (or NULL if not found)
-		err = queries.UpdateScrobbleReleaseYear(ctx, db.UpdateScrobbleReleaseYearParams{
-			ID:          scrobble.ID,
-			ReleaseYear: releaseYear,
-		})
-		if err != nil {
-			log.Printf("Failed to update scrobble %v: %v", scrobble.ID, err)
-			continue
-		}
-
-		processed++
-		if processed%100 == 0 {
-			log.Printf("Progress: %d/%d scrobbles processed", processed, len(scrobbles))
-		}
-	}
-	log.Printf("Pass 2 complete: %d found via fuzzy, %d not found (took %v)", fuzzyFound, notFound, time.Since(fuzzyStartTime))
-
-	log.Printf("Release year lookup complete: processed=%d, mbid_found=%d, fuzzy_found=%d, not_found=%d",
-		processed, mbidFound, fuzzyFound, notFound)
-	return processed, mbidFound, fuzzyFound, notFound, nil
-}
-
-func findReleaseYearByAlbumMbid(ctx context.Context, albumMbid string) (*int, error) {
+func findReleaseYearByAlbumMbid(ctx context.Context, albumMbid string) (ReleaseYearMatch, error) {
 	log.Printf("Looking up release year by album MBID: %s", albumMbid)
 
+	// Last.fm keeps MBIDs of releases MusicBrainz has since merged; those resolve through the redirect
 	query := `
-		SELECT rgm.first_release_date_year
-		FROM musicbrainz.release r
+		WITH target AS (
+			SELECT id, false AS redirected FROM musicbrainz.release WHERE gid = $1::uuid
+			UNION ALL
+			SELECT new_id, true FROM musicbrainz.release_gid_redirect WHERE gid = $1::uuid
+		)
+		SELECT
+			rgm.first_release_date_year,
+			rgm.first_release_date_month,
+			rgm.first_release_date_day,
+			rg.gid::text,
+			EXISTS (
+				SELECT 1
+				FROM musicbrainz.release_group_secondary_type_join rgst
+				JOIN musicbrainz.release_group_secondary_type st ON rgst.secondary_type = st.id
+				WHERE rgst.release_group = rg.id
+				  AND lower(st.name) = ANY($2::text[])
+			),
+			target.redirected
+		FROM target
+		JOIN musicbrainz.release r ON r.id = target.id
 		JOIN musicbrainz.release_group rg ON r.release_group = rg.id
 		LEFT JOIN musicbrainz.release_group_meta rgm ON rg.id = rgm.id
-		WHERE r.gid = $1::uuid
+		ORDER BY target.redirected
 		LIMIT 1
 	`
 
-	var year *int
-	err := mbPool.QueryRow(ctx, query, albumMbid).Scan(&year)
+	match := ReleaseYearMatch{Method: releaseYearMethodAlbumMbid, Confidence: releaseYearConfidenceMbid}
+	var excluded bool
+	err := mbPool.QueryRow(ctx, query, albumMbid, releaseYearConfig.ExcludedSecondaryTypes).Scan(&match.Year, &match.Month, &match.Day, &match.ReleaseGroupMbid, &excluded, &match.Redirected)
 	if err != nil {
 		log.Printf("Album MBID lookup failed for %s: %v", albumMbid, err)
-		return nil, err
+		return ReleaseYearMatch{}, err
+	}
+	if match.Redirected {
+		log.Printf("Album MBID %s was merged, followed the redirect to release group %s", albumMbid, match.ReleaseGroupMbid.String)
+	}
+
+	// A compilation or live album's year isn't the song's; leave the year unset so the
+	// scrobble falls through to the recording's own release groups
+	if excluded {
+		log.Printf("Album MBID %s is an excluded release group type, falling back to the recording", albumMbid)
+		match.Year, match.Month, match.Day = pgtype.Int4{}, pgtype.Int4{}, pgtype.Int4{}
+		return match, nil
 	}
 
-	if year != nil {
-		log.Printf("Found release year %d for album MBID %s", *year, albumMbid)
+	if match.Year.Valid {
+		log.Printf("Found release year %d for album MBID %s", match.Year.Int32, albumMbid)
 	} else {
 		log.Printf("No release year found for album MBID %s", albumMbid)
 	}
 
-	return year, nil
+	return match, nil
 }
 
-func findReleaseYearByTrackMbid(ctx context.Context, trackMbid string) (*int, error) {
+// releaseGroupOrder ranks the release groups a recording appears on: groups with a year first,
+// then groups without an excluded secondary type ($3, lowercased names), then (when $2 is true)
+// plain Album/Single/EP groups without any secondary type, then the earliest date. Within a year,
+// a known month or day sorts before an unknown one. Excluded groups only win when the recording
+// appears on nothing else. The query must join
+// release_group rg, release_group_meta rgm and release_group_primary_type rgpt.
+const releaseGroupOrder = `
+	rgm.first_release_date_year IS NULL,
+	EXISTS (
+		SELECT 1
+		FROM musicbrainz.release_group_secondary_type_join rgst
+		JOIN musicbrainz.release_group_secondary_type st ON rgst.secondary_type = st.id
+		WHERE rgst.release_group = rg.id
+		  AND lower(st.name) = ANY($3::text[])
+	),
+	CASE
+		WHEN $2::boolean
+			AND rgpt.name IN ('Album', 'Single', 'EP')
+			AND NOT EXISTS (
+				SELECT 1
+				FROM musicbrainz.release_group_secondary_type_join rgst
+				WHERE rgst.release_group = rg.id
+			)
+		THEN 0
+		ELSE 1
+	END,
+	rgm.first_release_date_year,
+	rgm.first_release_date_month,
+	rgm.first_release_date_day
+`
+
+func findReleaseYearByTrackMbid(ctx context.Context, trackMbid string) (ReleaseYearMatch, error) {
 	log.Printf("Looking up release year by track MBID: %s", trackMbid)
 
+	// Earliest release group the recording appears on, not whichever release comes back first.
+	// A merged recording's old MBID resolves through the redirect.
 	query := `
-		SELECT rgm.first_release_date_year
-		FROM musicbrainz.recording r
+		WITH target AS (
+			SELECT id, false AS redirected FROM musicbrainz.recording WHERE gid = $1::uuid
+			UNION ALL
+			SELECT new_id, true FROM musicbrainz.recording_gid_redirect WHERE gid = $1::uuid
+		)
+		SELECT
+			rgm.first_release_date_year,
+			rgm.first_release_date_month,
+			rgm.first_release_date_day,
+			rg.gid::text,
+			r.gid::text,
+			target.redirected
+		FROM target
+		JOIN musicbrainz.recording r ON r.id = target.id
 		JOIN musicbrainz.track t ON r.id = t.recording
 		JOIN musicbrainz.medium m ON t.medium = m.id
 		JOIN musicbrainz.release rel ON m.release = rel.id
 		JOIN musicbrainz.release_group rg ON rel.release_group = rg.id
 		LEFT JOIN musicbrainz.release_group_meta rgm ON rg.id = rgm.id
-		WHERE r.gid = $1::uuid
+		LEFT JOIN musicbrainz.release_group_primary_type rgpt ON rg.type = rgpt.id
+		ORDER BY target.redirected, ` + releaseGroupOrder + `
 		LIMIT 1
 	`
 
-	var year *int
-	err := mbPool.QueryRow(ctx, query, trackMbid).Scan(&year)
+	match := ReleaseYearMatch{Method: releaseYearMethodTrackMbid, Confidence: releaseYearConfidenceMbid}
+	err := mbPool.QueryRow(ctx, query, trackMbid, releaseYearConfig.PreferPrimaryTypes, releaseYearConfig.ExcludedSecondaryTypes).Scan(&match.Year, &match.Month, &match.Day, &match.ReleaseGroupMbid, &match.RecordingMbid, &match.Redirected)
 	if err != nil {
 		log.Printf("Track MBID lookup failed for %s: %v", trackMbid, err)
-		return nil, err
+		return ReleaseYearMatch{}, err
+	}
+	if match.Redirected {
+		log.Printf("Track MBID %s was merged, followed the redirect to recording %s", trackMbid, match.RecordingMbid.String)
 	}
 
-	if year != nil {
-		log.Printf("Found release year %d for track MBID %s", *year, trackMbid)
+	if match.Year.Valid {
+		log.Printf("Found release year %d for track MBID %s", match.Year.Int32, trackMbid)
 	} else {
 		log.Printf("No release year found for track MBID %s", trackMbid)
 	}
 
-	return year, nil
+	return match, nil
 }
 
 // preprocessArtistName normalizes artist names for better matching
@@ -700,6 +857,73 @@ func preprocessTrackName(name string) string {
 	return strings.TrimSpace(name)
 }
 
+// preprocessAlbumName strips edition notes such as "(Deluxe Edition)" or " - 2011 Remaster"
+func preprocessAlbumName(name string) string {
+	name = strings.TrimSpace(name)
+
+	// Keywords that indicate an edition rather than part of the title (case-insensitive)
+	editionKeywords := []string{
+		"remaster", "deluxe", "edition", "expanded", "anniversary",
+		"bonus", "version", "reissue",
+	}
+	isEditionNote := func(s string) bool {
+		s = strings.ToLower(s)
+		for _, keyword := range editionKeywords {
+			if strings.Contains(s, keyword) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Strip content after " - " if it names an edition
+	if idx := strings.Index(name, " - "); idx != -1 && isEditionNote(name[idx+3:]) {
+		name = strings.TrimSpace(name[:idx])
+	}
+
+	// Strip trailing parenthesized or bracketed edition notes, keeping at least some title
+	for {
+		var open string
+		switch {
+		case strings.HasSuffix(name, ")"):
+			open = "("
+		case strings.HasSuffix(name, "]"):
+			open = "["
+		default:
+			return name
+		}
+
+		start := strings.LastIndex(name, open)
+		if start <= 0 || !isEditionNote(name[start+1:len(name)-1]) {
+			return name
+		}
+		name = strings.TrimSpace(name[:start])
+	}
+}
+
+// splitArtistCredit splits a collaboration into its credited artists, in order and without duplicates
+func splitArtistCredit(name string) []string {
+	separators := []string{" & ", " feat. ", " featuring ", " x ", ","}
+
+	parts := []string{name}
+	for _, sep := range separators {
+		var split []string
+		for _, part := range parts {
+			split = append(split, strings.Split(part, sep)...)
+		}
+		parts = split
+	}
+
+	var artists []string
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part != "" && !slices.Contains(artists, part) {
+			artists = append(artists, part)
+		}
+	}
+	return artists
+}
+
 // extractFirstArtist extracts the first artist from a collaboration
 func extractFirstArtist(name string) string {
 	separators := []string{" & ", " feat. ", " featuring ", " x ", ","}
@@ -713,7 +937,64 @@ func extractFirstArtist(name string) string {
 	return name
 }
 
-func findReleaseYearByArtistAndTrack(ctx context.Context, artistName, trackName string) (*int, error) {
+// errReleaseYearNotFound means MusicBrainz has no matching recording with a release year
+var errReleaseYearNotFound = errors.New("no release year found")
+
+// findReleaseYearByArtistAndAlbum searches release groups by artist and album name, trying just the
+// first artist if the full credit finds nothing. With an artist MBID, that artist's release groups are
+// searched first. A match without a year means the album was found but its year can't be used for the track.
+func findReleaseYearByArtistAndAlbum(ctx context.Context, artistName, artistMbid, albumName string) (ReleaseYearMatch, error) {
+	startTime := time.Now()
+	log.Printf("Album search for artist='%s', album='%s'", artistName, albumName)
+
+	processedArtist := preprocessArtistName(artistName)
+	processedAlbum := preprocessAlbumName(albumName)
+
+	// An artist MBID Last.fm got wrong finds nothing, and the name search below takes over
+	err := pgx.ErrNoRows
+	var match ReleaseYearMatch
+	if artistMbid != "" {
+		match, err = tryFindAlbumReleaseYearByArtistMbid(ctx, artistMbid, processedAlbum)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return ReleaseYearMatch{}, fmt.Errorf("album search failed: %w", err)
+		}
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		match, err = tryFindAlbumReleaseYear(ctx, processedArtist, processedAlbum)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return ReleaseYearMatch{}, fmt.Errorf("album search failed: %w", err)
+		}
+	}
+
+	// Fallback: Try with just the first artist
+	if errors.Is(err, pgx.ErrNoRows) {
+		if firstArtist := extractFirstArtist(processedArtist); firstArtist != processedArtist {
+			log.Printf("Fallback: trying first artist only: '%s'", firstArtist)
+			match, err = tryFindAlbumReleaseYear(ctx, firstArtist, processedAlbum)
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return ReleaseYearMatch{}, fmt.Errorf("album search failed: %w", err)
+			}
+			match.Confidence *= releaseYearFirstArtistConfidenceScale
+		}
+	}
+
+	duration := time.Since(startTime)
+	if err != nil {
+		log.Printf("Album search found no release group for '%s - %s' (took %v)", artistName, albumName, duration)
+		return ReleaseYearMatch{}, errReleaseYearNotFound
+	}
+
+	match.Method = releaseYearMethodFuzzyAlbum
+	if match.Year.Valid {
+		log.Printf("Album search found release year %d for '%s - %s' (took %v)", match.Year.Int32, artistName, albumName, duration)
+	}
+	return match, nil
+}
+
+// findReleaseYearByArtistAndTrack searches recordings by artist and track name, falling back to the first
+// artist and then to transliterated names. With an artist MBID, that artist's recordings are searched first.
+func findReleaseYearByArtistAndTrack(ctx context.Context, artistName, artistMbid, trackName string) (ReleaseYearMatch, error) {
 	startTime := time.Now()
 	log.Printf("Fuzzy search for artist='%s', track='%s'", artistName, trackName)
 
@@ -726,74 +1007,70 @@ func findReleaseYearByArtistAndTrack(ctx context.Context, artistName, trackName
 			artistName, processedArtist, trackName, processedTrack)
 	}
 
+	// Try the artist Last.fm identified; a wrong MBID finds nothing and the name searches take over
+	if artistMbid != "" {
+		match, err := tryFindReleaseYearByArtistMbid(ctx, artistMbid, processedTrack)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return ReleaseYearMatch{}, fmt.Errorf("fuzzy search failed: %w", err)
+		}
+		if err == nil && match.Year.Valid {
+			duration := time.Since(startTime)
+			log.Printf("Fuzzy search found release year %d by artist MBID for '%s - %s' (took %v)", match.Year.Int32, artistName, trackName, duration)
+			match.Method = releaseYearMethodFuzzy
+			return match, nil
+		}
+	}
+
 	// Try with preprocessed names
-	year, err := tryFindReleaseYear(ctx, processedArtist, processedTrack)
-	if err == nil && year != nil {
+	match, err := tryFindReleaseYear(ctx, processedArtist, processedTrack)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return ReleaseYearMatch{}, fmt.Errorf("fuzzy search failed: %w", err)
+	}
+	if err == nil && match.Year.Valid {
 		duration := time.Since(startTime)
-		log.Printf("Fuzzy search found release year %d for '%s - %s' (took %v)", *year, artistName, trackName, duration)
-		return year, nil
+		log.Printf("Fuzzy search found release year %d for '%s - %s' (took %v)", match.Year.Int32, artistName, trackName, duration)
+		match.Method = releaseYearMethodFuzzy
+		return match, nil
 	}
 
 	// Fallback: Try with just the first artist
 	firstArtist := extractFirstArtist(processedArtist)
 	if firstArtist != processedArtist {
 		log.Printf("Fallback: trying first artist only: '%s'", firstArtist)
-		year, err = tryFindReleaseYear(ctx, firstArtist, processedTrack)
-		if err == nil && year != nil {
+		match, err = tryFindReleaseYear(ctx, firstArtist, processedTrack)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return ReleaseYearMatch{}, fmt.Errorf("fuzzy search failed: %w", err)
+		}
+		if err == nil && match.Year.Valid {
 			duration := time.Since(startTime)
-			log.Printf("Fuzzy search found release year %d via first artist fallback (took %v)", *year, duration)
-			return year, nil
+			log.Printf("Fuzzy search found release year %d via first artist fallback (took %v)", match.Year.Int32, duration)
+			match.Method = releaseYearMethodFuzzyFirstArtist
+			match.Confidence *= releaseYearFirstArtistConfidenceScale
+			return match, nil
 		}
 	}
 
-	duration := time.Since(startTime)
-	log.Printf("Fuzzy search found no release year for '%s - %s' (took %v)", artistName, trackName, duration)
-	return nil, fmt.Errorf("no release year found")
-}
-
-// tryFindReleaseYear performs the actual two-step database lookup
-func tryFindReleaseYear(ctx context.Context, artistName, trackName string) (*int, error) {
-	// Step 1: Find the artist first (including aliases)
-	artistQuery := `
-		SELECT DISTINCT a.id, a.name
-		FROM musicbrainz.artist a
-		LEFT JOIN musicbrainz.artist_alias aa ON a.id = aa.artist
-		WHERE a.name ILIKE '%' || $1 || '%'
-		   OR aa.name ILIKE '%' || $1 || '%'
-		LIMIT 1
-	`
-
-	var artistID int
-	var foundArtistName string
-	err := mbPool.QueryRow(ctx, artistQuery, strings.TrimSpace(artistName)).Scan(&artistID, &foundArtistName)
-	if err != nil {
-		return nil, err
-	}
-
-	log.Printf("Found artist '%s' (ID: %d) for search '%s'", foundArtistName, artistID, artistName)
-
-	// Step 2: Find the recording by that artist
-	recordingQuery := `
-		SELECT rgm.first_release_date_year
-		FROM musicbrainz.recording r
-		JOIN musicbrainz.artist_credit ac ON r.artist_credit = ac.id
-		JOIN musicbrainz.artist_credit_name acn ON ac.id = acn.artist_credit
-		JOIN musicbrainz.track t ON r.id = t.recording
-		JOIN musicbrainz.medium m ON t.medium = m.id
-		JOIN musicbrainz.release rel ON m.release = rel.id
-		JOIN musicbrainz.release_group rg ON rel.release_group = rg.id
-		LEFT JOIN musicbrainz.release_group_meta rgm ON rg.id = rgm.id
-		WHERE
-			acn.artist = $1
-			AND r.name ILIKE '%' || $2 || '%'
-		LIMIT 1
-	`
-
-	var year *int
-	err = mbPool.QueryRow(ctx, recordingQuery, artistID, strings.TrimSpace(trackName)).Scan(&year)
-	if err != nil {
-		return nil, err
+	// Fallback: Romanize native-script names, for artists MusicBrainz only knows by a Latin name.
+	// Recording titles often stay in native script, so the original title is tried first.
+	translitArtist := transliterate(processedArtist)
+	if translitArtist != processedArtist {
+		for _, track := range slices.Compact([]string{processedTrack, transliterate(processedTrack)}) {
+			log.Printf("Fallback: trying transliterated names: artist='%s', track='%s'", translitArtist, track)
+			match, err = tryFindReleaseYear(ctx, translitArtist, track)
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return ReleaseYearMatch{}, fmt.Errorf("fuzzy search failed: %w", err)
+			}
+			if err == nil && match.Year.Valid {
+				duration := time.Since(startTime)
+				log.Printf("Fuzzy search found release year %d via transliteration fallback (took %v)", match.Year.Int32, duration)
+				match.Method = releaseYearMethodFuzzyTranslit
+				match.Confidence *= releaseYearTranslitConfidenceScale
+				return match, nil
+			}
+		}
 	}
 
-	return year, nil
+	duration := time.Since(startTime)
+	log.Printf("Fuzzy search found no release year for '%s - %s' (took %v)", artistName, trackName, duration)
+	return ReleaseYearMatch{}, errReleaseYearNotFound
 }
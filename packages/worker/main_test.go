@@ -1,6 +1,9 @@
 package main
 
-import "testing"
+import (
+	"slices"
+	"testing"
+)
 
 func TestPreprocessArtistName(t *testing.T) {
 	tests := []struct {
@@ -113,6 +116,97 @@ func TestPreprocessTrackName(t *testing.T) {
 	}
 }
 
+func TestPreprocessAlbumName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "strips remaster suffix",
+			input:    "Procol Harum - 2009 Remaster",
+			expected: "Procol Harum",
+		},
+		{
+			name:     "strips deluxe edition parenthetical",
+			input:    "Random Access Memories (10th Anniversary Edition)",
+			expected: "Random Access Memories",
+		},
+		{
+			name:     "strips bracketed edition",
+			input:    "OK Computer [Remastered]",
+			expected: "OK Computer",
+		},
+		{
+			name:     "strips stacked edition notes",
+			input:    "Rumours (Super Deluxe) [2013 Remaster]",
+			expected: "Rumours",
+		},
+		{
+			name:     "keeps non-edition parenthetical",
+			input:    "(What's the Story) Morning Glory?",
+			expected: "(What's the Story) Morning Glory?",
+		},
+		{
+			name:     "keeps title that is only a parenthetical",
+			input:    "(Deluxe)",
+			expected: "(Deluxe)",
+		},
+		{
+			name:     "keeps non-edition dash suffix",
+			input:    "Music - The Best Of",
+			expected: "Music - The Best Of",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := preprocessAlbumName(tt.input)
+			if result != tt.expected {
+				t.Errorf("preprocessAlbumName(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSplitArtistCredit(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "single artist",
+			input:    "Daft Punk",
+			expected: []string{"Daft Punk"},
+		},
+		{
+			name:     "two artists",
+			input:    "Calvin Harris & Dua Lipa",
+			expected: []string{"Calvin Harris", "Dua Lipa"},
+		},
+		{
+			name:     "mixed separators",
+			input:    "A & B feat. C",
+			expected: []string{"A", "B", "C"},
+		},
+		{
+			name:     "drops duplicates",
+			input:    "A feat. B & A",
+			expected: []string{"A", "B"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := splitArtistCredit(tt.input)
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("splitArtistCredit(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestExtractFirstArtist(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// stripDiacritics decomposes characters and drops the combining marks, so "é" becomes "e"
+var stripDiacritics = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+// foldLetters spells out letters that don't decompose into a base letter and a mark
+var foldLetters = strings.NewReplacer(
+	"ß", "ss", "æ", "ae", "œ", "oe", "ø", "o", "đ", "d", "ð", "d", "ł", "l", "þ", "th", "ı", "i",
+)
+
+// foldPunctuation maps typographic quotes and dashes to their ASCII forms and spells out "&"
+var foldPunctuation = strings.NewReplacer(
+	"‘", "'", "’", "'", "‚", "'", "‛", "'", "′", "'", "`", "'", "´", "'",
+	"“", "\"", "”", "\"", "„", "\"", "″", "\"",
+	"‐", "-", "‑", "-", "‒", "-", "–", "-", "—", "-", "―", "-", "−", "-",
+	"&", " and ",
+)
+
+// Articles dropped from the start of a name, or from the end in sort-name form ("Beatles, The")
+var nameArticles = []string{"the", "a", "an"}
+
+// normalizeName folds a name into the form used to compare scrobble names with MusicBrainz names:
+// NFKC (full-width and compatibility characters), lowercase, no diacritics, ASCII quotes and dashes,
+// "and" for "&", no leading or trailing article and single spaces. Both sides of a comparison must
+// be normalized for it to mean anything.
+func normalizeName(name string) string {
+	name = norm.NFKC.String(name)
+	name = strings.ToLower(name)
+	if folded, _, err := transform.String(stripDiacritics, name); err == nil {
+		name = folded
+	}
+	name = foldLetters.Replace(name)
+	name = foldPunctuation.Replace(name)
+	name = strings.Join(strings.Fields(name), " ")
+
+	for _, article := range nameArticles {
+		// Keep the article when it is the whole name, like the band "A"
+		if rest, ok := strings.CutPrefix(name, article+" "); ok {
+			name = rest
+			break
+		}
+		if rest, ok := strings.CutSuffix(name, ", "+article); ok {
+			name = rest
+			break
+		}
+	}
+
+	return name
+}
+
+// foldSearchName folds a search string the way musicbrainz.lastyearfm_fold folds MusicBrainz names
+// for the trigram prefilter: NFKC, lowercase and no diacritics. It keeps punctuation and articles,
+// which normalizeName handles when the candidates are rescored.
+func foldSearchName(name string) string {
+	name = norm.NFKC.String(name)
+	name = strings.ToLower(name)
+	if folded, _, err := transform.String(stripDiacritics, name); err == nil {
+		name = folded
+	}
+	return foldLetters.Replace(name)
+}
+
+// trigramSimilarity mirrors pg_trgm's similarity() for already normalized names: each word
+// (a run of letters and digits) is padded with two spaces in front and one behind and split
+// into trigrams, and the result is the shared fraction of the two trigram sets.
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+func trigrams(s string) map[string]bool {
+	set := make(map[string]bool)
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "strips diacritics",
+			input:    "Beyoncé",
+			expected: "beyonce",
+		},
+		{
+			name:     "strips stacked diacritics",
+			input:    "Sigur Rós & Björk",
+			expected: "sigur ros and bjork",
+		},
+		{
+			name:     "spells out letters without a decomposition",
+			input:    "Mø & Straßenjungs",
+			expected: "mo and strassenjungs",
+		},
+		{
+			name:     "folds curly apostrophes",
+			input:    "Don’t Stop Me Now",
+			expected: "don't stop me now",
+		},
+		{
+			name:     "folds curly double quotes",
+			input:    "“Heroes”",
+			expected: "\"heroes\"",
+		},
+		{
+			name:     "folds en and em dashes",
+			input:    "Jay–Z — Live",
+			expected: "jay-z - live",
+		},
+		{
+			name:     "spells out ampersand",
+			input:    "Simon & Garfunkel",
+			expected: "simon and garfunkel",
+		},
+		{
+			name:     "ampersand without spaces",
+			input:    "R&B",
+			expected: "r and b",
+		},
+		{
+			name:     "folds full-width characters",
+			input:    "ＡＢＣ　Ｄｅｆ",
+			expected: "abc def",
+		},
+		{
+			name:     "drops leading article",
+			input:    "The Beatles",
+			expected: "beatles",
+		},
+		{
+			name:     "drops trailing sort-name article",
+			input:    "Beatles, The",
+			expected: "beatles",
+		},
+		{
+			name:     "keeps article that is the whole name",
+			input:    "The",
+			expected: "the",
+		},
+		{
+			name:     "keeps article inside the name",
+			input:    "Florence and the Machine",
+			expected: "florence and the machine",
+		},
+		{
+			name:     "collapses whitespace",
+			input:    "  Daft   Punk ",
+			expected: "daft punk",
+		},
+		{
+			name:     "leaves non-Latin scripts alone",
+			input:    "米津玄師",
+			expected: "米津玄師",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := normalizeName(tt.input)
+			if result != tt.expected {
+				t.Errorf("normalizeName(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTrigramSimilarity(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected float64
+	}{
+		{
+			name:     "identical names",
+			a:        "beyonce",
+			b:        "beyonce",
+			expected: 1,
+		},
+		{
+			name:     "punctuation splits words",
+			a:        "don't stop",
+			b:        "dont stop",
+			expected: 8.0 / 13.0,
+		},
+		{
+			name:     "word order doesn't matter",
+			a:        "punk daft",
+			b:        "daft punk",
+			expected: 1,
+		},
+		{
+			name:     "partial overlap",
+			a:        "yes",
+			b:        "yesterday",
+			expected: 3.0 / 11.0,
+		},
+		{
+			name:     "nothing in common",
+			a:        "abc",
+			b:        "xyz",
+			expected: 0,
+		},
+		{
+			name:     "empty name",
+			a:        "",
+			b:        "abc",
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := trigramSimilarity(tt.a, tt.b)
+			if math.Abs(result-tt.expected) > 1e-9 {
+				t.Errorf("trigramSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFoldSearchNameKeepsPrefilterMatches(t *testing.T) {
+	// Pairs the raw pg_trgm prefilter drops at the default threshold but the folded one keeps
+	tests := []struct {
+		name   string
+		search string
+		mbName string
+	}{
+		{
+			name:   "missing diacritic in a short name",
+			search: "Bjork",
+			mbName: "Björk",
+		},
+		{
+			name:   "full-width input",
+			search: "ＢＥＹＯＮＣＥ",
+			mbName: "Beyoncé",
+		},
+		{
+			name:   "letter without a decomposition",
+			search: "Mo",
+			mbName: "Mø",
+		},
+	}
+
+	const threshold = 0.5
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := trigramSimilarity(strings.ToLower(tt.search), strings.ToLower(tt.mbName))
+			if raw >= threshold {
+				t.Fatalf("raw similarity of %q and %q = %v, want below %v", tt.search, tt.mbName, raw, threshold)
+			}
+			folded := trigramSimilarity(foldSearchName(tt.search), foldSearchName(tt.mbName))
+			if folded < threshold {
+				t.Errorf("folded similarity of %q and %q = %v, want at least %v", tt.search, tt.mbName, folded, threshold)
+			}
+		})
+	}
+}
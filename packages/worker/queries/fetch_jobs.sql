@@ -0,0 +1,208 @@
+-- name: CreateFetchJob :one
+-- Returns no row when an identical job is already active (fetch_jobs_active_unique)
+INSERT INTO fetch_jobs (kind, username, year)
+VALUES ($1, $2, $3)
+ON CONFLICT (kind, username, year) WHERE status IN ('pending', 'fetching', 'augmenting') DO NOTHING
+RETURNING id;
+
+-- name: GetActiveFetchJob :one
+SELECT id
+FROM fetch_jobs
+WHERE kind = $1
+  AND username = $2
+  AND year = $3
+  AND status IN ('pending', 'fetching', 'augmenting')
+ORDER BY "createdAt" DESC
+LIMIT 1;
+
+-- name: ClaimNextFetchJob :one
+UPDATE fetch_jobs
+SET
+    status = CASE WHEN kind = 'import' THEN 'fetching' ELSE 'augmenting' END,
+    "claimedBy" = sqlc.arg('claimed_by'),
+    "heartbeatAt" = now(),
+    "startedAt" = now(),
+    "updatedAt" = now()
+WHERE id = (
+    SELECT id
+    FROM fetch_jobs
+    WHERE status = 'pending'
+    ORDER BY "createdAt"
+    FOR UPDATE SKIP LOCKED
+    LIMIT 1
+)
+RETURNING id, kind, username, year;
+
+-- name: UpdateImportJobProgress :exec
+UPDATE fetch_jobs
+SET
+    phase = 'fetch',
+    progress = $2,
+    "totalPages" = $3,
+    "totalScrobbles" = $4,
+    "scrobblesInserted" = $5,
+    "scrobblesAlreadyPresent" = $6,
+    "updatedAt" = now()
+WHERE id = $1;
+
+-- name: UpdateReleaseYearJobProgress :exec
+UPDATE fetch_jobs
+SET
+    phase = $2,
+    progress = $3,
+    "totalScrobbles" = $4,
+    "mbidFound" = $5,
+    "fuzzyFound" = $6,
+    "notFound" = $7,
+    "mbidRedirected" = $8,
+    "updatedAt" = now()
+WHERE id = $1;
+
+-- name: UpdateGenreJobProgress :exec
+UPDATE fetch_jobs
+SET
+    phase = 'genres',
+    progress = $2,
+    "totalScrobbles" = $3,
+    "genresFound" = $4,
+    "genresNotFound" = $5,
+    "genresTimedOut" = $6,
+    "genresErrors" = $7,
+    "genresCacheHits" = $8,
+    "updatedAt" = now()
+WHERE id = $1;
+
+-- name: GetFetchJob :one
+SELECT
+    id,
+    kind,
+    username,
+    year,
+    status,
+    phase,
+    progress,
+    "totalPages",
+    "totalScrobbles",
+    "scrobblesInserted",
+    "scrobblesAlreadyPresent",
+    "mbidFound",
+    "mbidRedirected",
+    "fuzzyFound",
+    "notFound",
+    "genresFound",
+    "genresNotFound",
+    "genresTimedOut",
+    "genresErrors",
+    "genresCacheHits",
+    "errorMessage",
+    "genreErrorMessage",
+    "createdAt",
+    "updatedAt",
+    "startedAt",
+    "finishedAt"
+FROM fetch_jobs
+WHERE id = $1;
+
+-- name: ListFetchJobs :many
+SELECT
+    id,
+    kind,
+    username,
+    year,
+    status,
+    phase,
+    progress,
+    "totalPages",
+    "totalScrobbles",
+    "scrobblesInserted",
+    "scrobblesAlreadyPresent",
+    "mbidFound",
+    "mbidRedirected",
+    "fuzzyFound",
+    "notFound",
+    "genresFound",
+    "genresNotFound",
+    "genresTimedOut",
+    "genresErrors",
+    "genresCacheHits",
+    "errorMessage",
+    "genreErrorMessage",
+    "createdAt",
+    "updatedAt",
+    "startedAt",
+    "finishedAt"
+FROM fetch_jobs
+WHERE (sqlc.narg('username')::varchar IS NULL OR username = sqlc.narg('username'))
+  AND (sqlc.narg('year')::integer IS NULL OR year = sqlc.narg('year'))
+ORDER BY "createdAt" DESC
+LIMIT sqlc.arg('limit');
+
+-- name: SetGenreJobError :exec
+UPDATE fetch_jobs
+SET
+    "genreErrorMessage" = $2,
+    "updatedAt" = now()
+WHERE id = $1;
+
+-- name: CompleteFetchJob :exec
+UPDATE fetch_jobs
+SET
+    status = 'completed',
+    "finishedAt" = now(),
+    "updatedAt" = now()
+WHERE id = $1;
+
+-- name: FailFetchJob :exec
+UPDATE fetch_jobs
+SET
+    status = 'failed',
+    "errorMessage" = $2,
+    "finishedAt" = now(),
+    "updatedAt" = now()
+WHERE id = $1;
+
+-- name: CancelFetchJob :exec
+UPDATE fetch_jobs
+SET
+    status = 'cancelled',
+    "finishedAt" = now(),
+    "updatedAt" = now()
+WHERE id = $1;
+
+-- name: CancelPendingFetchJob :execrows
+UPDATE fetch_jobs
+SET
+    status = 'cancelled',
+    "finishedAt" = now(),
+    "updatedAt" = now()
+WHERE id = $1
+  AND status = 'pending';
+
+-- name: TouchFetchJobHeartbeats :execrows
+UPDATE fetch_jobs
+SET "heartbeatAt" = now()
+WHERE "claimedBy" = $1
+  AND status IN ('fetching', 'augmenting');
+
+-- name: ReleaseFetchJob :exec
+-- Hands a job interrupted by shutdown back to the queue, unless another worker already took it over
+UPDATE fetch_jobs
+SET
+    status = 'pending',
+    "claimedBy" = NULL,
+    "heartbeatAt" = NULL,
+    "updatedAt" = now()
+WHERE id = $1
+  AND "claimedBy" = $2
+  AND status IN ('fetching', 'augmenting');
+
+-- name: RequeueStaleFetchJobs :execrows
+-- Jobs claimed before heartbeats existed have none and count as stale
+UPDATE fetch_jobs
+SET
+    status = 'pending',
+    "claimedBy" = NULL,
+    "heartbeatAt" = NULL,
+    "updatedAt" = now()
+WHERE status IN ('fetching', 'augmenting')
+  AND ("heartbeatAt" IS NULL OR "heartbeatAt" < now() - sqlc.arg('stale_after')::interval);
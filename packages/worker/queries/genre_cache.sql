@@ -0,0 +1,14 @@
+-- name: GetGenreCache :many
+SELECT "genreKey", genres
+FROM genre_cache
+WHERE "genreKey" = ANY(sqlc.arg('genre_keys')::text[])
+  AND rules = sqlc.arg('rules')
+  AND "lookedUpAt" > sqlc.arg('fresh_since');
+
+-- name: UpsertGenreCache :exec
+INSERT INTO genre_cache ("genreKey", genres, rules, "lookedUpAt")
+VALUES ($1, $2, $3, now())
+ON CONFLICT ("genreKey") DO UPDATE SET
+    genres = EXCLUDED.genres,
+    rules = EXCLUDED.rules,
+    "lookedUpAt" = EXCLUDED."lookedUpAt";
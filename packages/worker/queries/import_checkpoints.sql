@@ -0,0 +1,36 @@
+-- name: GetImportCheckpoint :one
+SELECT
+    "windowEndUnix",
+    "lastCompletedPage",
+    "totalPages",
+    "lastScrobbledAtUnix",
+    completed
+FROM import_checkpoints
+WHERE username = $1
+  AND year = $2;
+
+-- name: StartImportCheckpoint :exec
+INSERT INTO import_checkpoints (username, year, "windowEndUnix")
+VALUES ($1, $2, $3)
+ON CONFLICT (username, year)
+DO UPDATE SET
+    "windowEndUnix" = EXCLUDED."windowEndUnix",
+    "lastCompletedPage" = 0,
+    "totalPages" = NULL,
+    "lastScrobbledAtUnix" = NULL,
+    completed = false;
+
+-- name: UpdateImportCheckpointProgress :exec
+UPDATE import_checkpoints
+SET
+    "lastCompletedPage" = $3,
+    "totalPages" = $4,
+    "lastScrobbledAtUnix" = $5
+WHERE username = $1
+  AND year = $2;
+
+-- name: CompleteImportCheckpoint :exec
+UPDATE import_checkpoints
+SET completed = true
+WHERE username = $1
+  AND year = $2;
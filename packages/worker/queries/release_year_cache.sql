@@ -0,0 +1,103 @@
+-- name: GetAlbumYearCache :many
+SELECT "albumMbid", "releaseYear", "releaseMonth", "releaseDay", "releaseGroupMbid", redirected
+FROM album_year_cache
+WHERE "albumMbid" = ANY(sqlc.arg('album_mbids')::varchar[])
+  AND rules = sqlc.arg('rules')
+  AND ("releaseYear" IS NOT NULL OR "lookedUpAt" > sqlc.arg('negative_since'));
+
+-- name: UpsertAlbumYearCache :exec
+INSERT INTO album_year_cache ("albumMbid", "releaseYear", "releaseMonth", "releaseDay", "releaseGroupMbid", redirected, rules, "lookedUpAt")
+VALUES ($1, $2, $3, $4, $5, $6, $7, now())
+ON CONFLICT ("albumMbid") DO UPDATE SET
+    "releaseYear" = EXCLUDED."releaseYear",
+    "releaseMonth" = EXCLUDED."releaseMonth",
+    "releaseDay" = EXCLUDED."releaseDay",
+    "releaseGroupMbid" = EXCLUDED."releaseGroupMbid",
+    redirected = EXCLUDED.redirected,
+    rules = EXCLUDED.rules,
+    "lookedUpAt" = EXCLUDED."lookedUpAt";
+
+-- name: GetRecordingYearCache :many
+SELECT "trackMbid", "releaseYear", "releaseMonth", "releaseDay", "releaseGroupMbid", redirected
+FROM recording_year_cache
+WHERE "trackMbid" = ANY(sqlc.arg('track_mbids')::varchar[])
+  AND rules = sqlc.arg('rules')
+  AND ("releaseYear" IS NOT NULL OR "lookedUpAt" > sqlc.arg('negative_since'));
+
+-- name: UpsertRecordingYearCache :exec
+INSERT INTO recording_year_cache ("trackMbid", "releaseYear", "releaseMonth", "releaseDay", "releaseGroupMbid", redirected, rules, "lookedUpAt")
+VALUES ($1, $2, $3, $4, $5, $6, $7, now())
+ON CONFLICT ("trackMbid") DO UPDATE SET
+    "releaseYear" = EXCLUDED."releaseYear",
+    "releaseMonth" = EXCLUDED."releaseMonth",
+    "releaseDay" = EXCLUDED."releaseDay",
+    "releaseGroupMbid" = EXCLUDED."releaseGroupMbid",
+    redirected = EXCLUDED.redirected,
+    rules = EXCLUDED.rules,
+    "lookedUpAt" = EXCLUDED."lookedUpAt";
+
+-- name: GetArtistTrackYearCache :many
+-- Matches every artist/track combination of the two lists; callers keep only the pairs they asked for
+SELECT "artistKey", "trackKey", "releaseYear", "releaseMonth", "releaseDay", method, "releaseGroupMbid", "recordingMbid", confidence
+FROM artist_track_year_cache
+WHERE "artistKey" = ANY(sqlc.arg('artist_keys')::text[])
+  AND "trackKey" = ANY(sqlc.arg('track_keys')::text[])
+  AND rules = sqlc.arg('rules')
+  AND ("releaseYear" IS NOT NULL OR "lookedUpAt" > sqlc.arg('negative_since'));
+
+-- name: UpsertArtistTrackYearCache :exec
+INSERT INTO artist_track_year_cache (
+    "artistKey",
+    "trackKey",
+    "releaseYear",
+    "releaseMonth",
+    "releaseDay",
+    method,
+    "releaseGroupMbid",
+    "recordingMbid",
+    confidence,
+    rules,
+    "lookedUpAt"
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, now())
+ON CONFLICT ("artistKey", "trackKey") DO UPDATE SET
+    "releaseYear" = EXCLUDED."releaseYear",
+    "releaseMonth" = EXCLUDED."releaseMonth",
+    "releaseDay" = EXCLUDED."releaseDay",
+    method = EXCLUDED.method,
+    "releaseGroupMbid" = EXCLUDED."releaseGroupMbid",
+    "recordingMbid" = EXCLUDED."recordingMbid",
+    confidence = EXCLUDED.confidence,
+    rules = EXCLUDED.rules,
+    "lookedUpAt" = EXCLUDED."lookedUpAt";
+
+-- name: GetArtistAlbumYearCache :many
+-- Matches every artist/album combination of the two lists; callers keep only the pairs they asked for
+SELECT "artistKey", "albumKey", "releaseYear", "releaseMonth", "releaseDay", "releaseGroupMbid", confidence
+FROM artist_album_year_cache
+WHERE "artistKey" = ANY(sqlc.arg('artist_keys')::text[])
+  AND "albumKey" = ANY(sqlc.arg('album_keys')::text[])
+  AND rules = sqlc.arg('rules')
+  AND ("releaseYear" IS NOT NULL OR "lookedUpAt" > sqlc.arg('negative_since'));
+
+-- name: UpsertArtistAlbumYearCache :exec
+INSERT INTO artist_album_year_cache (
+    "artistKey",
+    "albumKey",
+    "releaseYear",
+    "releaseMonth",
+    "releaseDay",
+    "releaseGroupMbid",
+    confidence,
+    rules,
+    "lookedUpAt"
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, now())
+ON CONFLICT ("artistKey", "albumKey") DO UPDATE SET
+    "releaseYear" = EXCLUDED."releaseYear",
+    "releaseMonth" = EXCLUDED."releaseMonth",
+    "releaseDay" = EXCLUDED."releaseDay",
+    "releaseGroupMbid" = EXCLUDED."releaseGroupMbid",
+    confidence = EXCLUDED.confidence,
+    rules = EXCLUDED.rules,
+    "lookedUpAt" = EXCLUDED."lookedUpAt";
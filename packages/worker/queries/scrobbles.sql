@@ -1,4 +1,5 @@
--- name: InsertScrobble :exec
+-- name: InsertScrobble :execrows
+-- Fallback for a page whose bulk load fails, so one bad row only skips itself
 INSERT INTO scrobbles (
     username,
     "trackName",
@@ -10,7 +11,51 @@ INSERT INTO scrobbles (
     "scrobbledAt",
     "scrobbledAtUnix",
     year
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT ON CONSTRAINT scrobbles_natural_key_unique DO NOTHING;
+
+-- name: CreateScrobbleStagingTable :exec
+-- Matches schema/scrobbles_staging.sql; COPY fills it and MergeScrobbleStagingTable empties it into scrobbles
+CREATE TEMP TABLE scrobbles_staging (
+    username varchar(256) NOT NULL,
+    "trackName" varchar(512) NOT NULL,
+    "trackMbid" varchar(36),
+    "artistName" varchar(512) NOT NULL,
+    "artistMbid" varchar(36),
+    "albumName" varchar(512),
+    "albumMbid" varchar(36),
+    "scrobbledAt" timestamp with time zone NOT NULL,
+    "scrobbledAtUnix" varchar(32) NOT NULL,
+    year integer NOT NULL
+) ON COMMIT DROP;
+
+-- name: MergeScrobbleStagingTable :execrows
+-- Duplicates within a page are collapsed by DISTINCT ON so the merge never conflicts with itself
+INSERT INTO scrobbles (
+    username,
+    "trackName",
+    "trackMbid",
+    "artistName",
+    "artistMbid",
+    "albumName",
+    "albumMbid",
+    "scrobbledAt",
+    "scrobbledAtUnix",
+    year
+)
+SELECT DISTINCT ON (username, "scrobbledAtUnix", "artistName", "trackName")
+    username,
+    "trackName",
+    "trackMbid",
+    "artistName",
+    "artistMbid",
+    "albumName",
+    "albumMbid",
+    "scrobbledAt",
+    "scrobbledAtUnix",
+    year
+FROM scrobbles_staging
+ON CONFLICT ON CONSTRAINT scrobbles_natural_key_unique DO NOTHING;
 
 -- name: GetScrobblesForReleaseYearLookup :many
 SELECT
@@ -27,9 +72,36 @@ WHERE username = $1
   AND "releaseYearFetched" = false
 ORDER BY "scrobbledAt";
 
--- name: UpdateScrobbleReleaseYear :exec
+-- name: UpdateScrobblesReleaseYear :execrows
+UPDATE scrobbles
+SET
+    "releaseYear" = sqlc.narg('release_year'),
+    "releaseMonth" = sqlc.narg('release_month'),
+    "releaseDay" = sqlc.narg('release_day'),
+    "releaseYearFetched" = true,
+    "releaseYearMethod" = sqlc.arg('method'),
+    "matchedReleaseGroupMbid" = sqlc.narg('release_group_mbid'),
+    "matchedRecordingMbid" = sqlc.narg('recording_mbid'),
+    "releaseYearConfidence" = sqlc.narg('confidence')
+WHERE id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: GetScrobblesForGenreLookup :many
+-- Genres come from the release year match, so only scrobbles whose release year lookup ran are included
+SELECT
+    id,
+    "artistMbid",
+    "matchedReleaseGroupMbid",
+    "matchedRecordingMbid"
+FROM scrobbles
+WHERE username = $1
+  AND year = $2
+  AND "releaseYearFetched" = true
+  AND "genresFetched" = false
+ORDER BY "scrobbledAt";
+
+-- name: UpdateScrobblesGenres :execrows
 UPDATE scrobbles
 SET
-    "releaseYear" = $2,
-    "releaseYearFetched" = true
-WHERE id = $1;
+    genres = sqlc.arg('genres')::text[],
+    "genresFetched" = true
+WHERE id = ANY(sqlc.arg('ids')::uuid[]);
@@ -0,0 +1,566 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"last-year-fm/worker/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Release year lookup phases, reported on fetch_jobs.phase
+const (
+	releaseYearPhaseMbid  = "mbid"
+	releaseYearPhaseFuzzy = "fuzzy"
+)
+
+// Release year match methods, stored on scrobbles.releaseYearMethod
+const (
+	releaseYearMethodAlbumMbid        = "album_mbid"
+	releaseYearMethodTrackMbid        = "track_mbid"
+	releaseYearMethodFuzzyAlbum       = "fuzzy_album"
+	releaseYearMethodFuzzy            = "fuzzy"
+	releaseYearMethodFuzzyFirstArtist = "fuzzy_first_artist"
+	releaseYearMethodFuzzyTranslit    = "fuzzy_transliterated"
+	releaseYearMethodNotFound         = "not_found"
+)
+
+// MBIDs identify the release or recording exactly. Fuzzy matches are as confident as their trigram
+// score, and dropping featured artists or transliterating the names scales that down further.
+const (
+	releaseYearConfidenceMbid             = 1.0
+	releaseYearFirstArtistConfidenceScale = 0.8
+	releaseYearTranslitConfidenceScale    = 0.8
+)
+
+// ReleaseYearMatch is a release date together with how and where in MusicBrainz it was found.
+// Year is not Valid when nothing matched or the match has no release year; Month and Day are
+// partial-date parts that MusicBrainz often doesn't know.
+type ReleaseYearMatch struct {
+	Year             pgtype.Int4
+	Month            pgtype.Int4
+	Day              pgtype.Int4
+	Method           string
+	ReleaseGroupMbid pgtype.Text
+	RecordingMbid    pgtype.Text
+	Confidence       float32
+	Redirected       bool // Found through the MBID of an entity MusicBrainz has since merged
+}
+
+// ReleaseYearConfig bounds the concurrency and duration of MusicBrainz lookups.
+// MBID lookups hit indexed columns and can run wide; fuzzy searches are slower trigram scans.
+type ReleaseYearConfig struct {
+	MbidConcurrency  int
+	FuzzyConcurrency int
+	MbidTimeout      time.Duration
+	FuzzyTimeout     time.Duration
+	NegativeCacheTTL time.Duration // How long a cached "not found" is trusted before MusicBrainz is asked again
+	FuzzyThreshold   float64       // Minimum trigram similarity for artist and recording candidates
+	FuzzyCandidates  int           // Artist candidates whose recordings are searched
+	AliasLocales     []string      // Lowercased language codes whose artist aliases rank like primary names
+
+	// Year resolution rules
+	PreferPrimaryTypes     bool     // Prefer Album/Single/EP release groups without secondary types over earlier compilations
+	ExcludedSecondaryTypes []string // Lowercased secondary types whose year is only used when nothing else matches
+}
+
+// releaseYearConfig is loaded in main once the environment is read
+var releaseYearConfig ReleaseYearConfig
+
+func loadReleaseYearConfig() ReleaseYearConfig {
+	return ReleaseYearConfig{
+		MbidConcurrency:  envInt("RELEASE_YEAR_MBID_WORKERS", 20),
+		FuzzyConcurrency: envInt("RELEASE_YEAR_FUZZY_WORKERS", 10),
+		MbidTimeout:      envDuration("RELEASE_YEAR_MBID_TIMEOUT", 5*time.Second),
+		FuzzyTimeout:     envDuration("RELEASE_YEAR_FUZZY_TIMEOUT", 30*time.Second),
+		NegativeCacheTTL: envDuration("RELEASE_YEAR_NEGATIVE_CACHE_TTL", 7*24*time.Hour),
+		FuzzyThreshold:   min(max(envFloat("RELEASE_YEAR_FUZZY_THRESHOLD", 0.5), 0), 1),
+		FuzzyCandidates:  max(envInt("RELEASE_YEAR_FUZZY_CANDIDATES", 5), 1),
+		AliasLocales:     lowerAll(envList("RELEASE_YEAR_ALIAS_LOCALES", []string{"en"})),
+
+		PreferPrimaryTypes:     envBool("RELEASE_YEAR_PREFER_PRIMARY_TYPES", true),
+		ExcludedSecondaryTypes: lowerAll(envList("RELEASE_YEAR_EXCLUDED_SECONDARY_TYPES", defaultExcludedSecondaryTypes)),
+	}
+}
+
+// defaultExcludedSecondaryTypes are MusicBrainz secondary types that re-release or re-record songs
+var defaultExcludedSecondaryTypes = []string{"Compilation", "Soundtrack", "Live", "Remix", "DJ-mix"}
+
+func lowerAll(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, value := range values {
+		lowered[i] = strings.ToLower(value)
+	}
+	return lowered
+}
+
+// Rules identifies the year resolution and fuzzy matching settings, so cached years resolved under other rules are ignored.
+// Long rule sets are hashed to fit the cache tables' rules column.
+func (c ReleaseYearConfig) Rules() string {
+	excluded := slices.Clone(c.ExcludedSecondaryTypes)
+	slices.Sort(excluded)
+	locales := slices.Clone(c.AliasLocales)
+	slices.Sort(locales)
+	rules := fmt.Sprintf("earliest_date;prefer_primary=%t;exclude=%s;fuzzy=trgm+fold+norm+translit+credits:%.2f;locales=%s",
+		c.PreferPrimaryTypes, strings.Join(excluded, ","), c.FuzzyThreshold, strings.Join(locales, ","))
+	if len(rules) > 128 {
+		sum := sha256.Sum256([]byte(rules))
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+	return rules
+}
+
+// ReleaseYearStats summarizes a release year lookup run
+type ReleaseYearStats struct {
+	Phase      string
+	Total      int
+	Processed  int
+	MbidFound  int
+	Redirected int // Part of MbidFound, resolved through a merged entity's old MBID
+	FuzzyFound int
+	NotFound   int
+	TimedOut   int // Timed out in any pass; unless a later pass answers them, left for the next run
+	Errors     int // Failed fuzzy searches, also left for the next run
+	CacheHits  int // Keys answered from the lookup caches without querying MusicBrainz
+}
+
+// releaseYearCounters are the lookup counters shared by the pass workers
+type releaseYearCounters struct {
+	processed  atomic.Int64
+	mbidFound  atomic.Int64
+	redirected atomic.Int64
+	fuzzyFound atomic.Int64
+	notFound   atomic.Int64
+	timedOut   atomic.Int64
+	errors     atomic.Int64
+	cacheHits  atomic.Int64
+}
+
+func (c *releaseYearCounters) snapshot(phase string, total int) ReleaseYearStats {
+	return ReleaseYearStats{
+		Phase:      phase,
+		Total:      total,
+		Processed:  int(c.processed.Load()),
+		MbidFound:  int(c.mbidFound.Load()),
+		Redirected: int(c.redirected.Load()),
+		FuzzyFound: int(c.fuzzyFound.Load()),
+		NotFound:   int(c.notFound.Load()),
+		TimedOut:   int(c.timedOut.Load()),
+		Errors:     int(c.errors.Load()),
+		CacheHits:  int(c.cacheHits.Load()),
+	}
+}
+
+// releaseYearGroup is a set of scrobbles that share a lookup key. The key is resolved once
+// using the first scrobble and the result is written to every scrobble in the group.
+type releaseYearGroup struct {
+	Key      string
+	Scrobble db.GetScrobblesForReleaseYearLookupRow
+	IDs      []pgtype.UUID
+}
+
+// groupScrobbles groups scrobbles by key in first-seen order, skipping scrobbles without a key
+func groupScrobbles(scrobbles []db.GetScrobblesForReleaseYearLookupRow, key func(db.GetScrobblesForReleaseYearLookupRow) string) []releaseYearGroup {
+	var groups []releaseYearGroup
+	index := make(map[string]int)
+
+	for _, scrobble := range scrobbles {
+		k := key(scrobble)
+		if k == "" {
+			continue
+		}
+
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, releaseYearGroup{Key: k, Scrobble: scrobble})
+		}
+		groups[i].IDs = append(groups[i].IDs, scrobble.ID)
+	}
+
+	return groups
+}
+
+func albumMbidKey(scrobble db.GetScrobblesForReleaseYearLookupRow) string {
+	return strings.ToLower(scrobble.AlbumMbid.String)
+}
+
+func trackMbidKey(scrobble db.GetScrobblesForReleaseYearLookupRow) string {
+	return strings.ToLower(scrobble.TrackMbid.String)
+}
+
+// fuzzyKey groups scrobbles whose names preprocess and normalize to the same search,
+// which findReleaseYearByArtistAndTrack can't tell apart
+func fuzzyKey(scrobble db.GetScrobblesForReleaseYearLookupRow) string {
+	track := normalizeName(preprocessTrackName(scrobble.TrackName))
+	return joinFuzzyKey(fuzzyArtistKey(scrobble), track)
+}
+
+// fuzzyArtistKey is the artist MBID when Last.fm has one, since the search is pinned to that artist
+// and two artists of the same name mustn't share a result, and the normalized name otherwise
+func fuzzyArtistKey(scrobble db.GetScrobblesForReleaseYearLookupRow) string {
+	if mbid := strings.ToLower(strings.TrimSpace(scrobble.ArtistMbid.String)); mbid != "" {
+		return "mbid:" + mbid
+	}
+	return normalizeName(preprocessArtistName(scrobble.ArtistName))
+}
+
+// albumNameKey groups scrobbles by normalized artist and album name, skipping scrobbles without an album
+func albumNameKey(scrobble db.GetScrobblesForReleaseYearLookupRow) string {
+	album := normalizeName(preprocessAlbumName(scrobble.AlbumName.String))
+	if album == "" {
+		return ""
+	}
+	return joinFuzzyKey(fuzzyArtistKey(scrobble), album)
+}
+
+// unresolvedScrobbles returns the scrobbles whose IDs are not in resolved
+func unresolvedScrobbles(scrobbles []db.GetScrobblesForReleaseYearLookupRow, resolved map[pgtype.UUID]bool) []db.GetScrobblesForReleaseYearLookupRow {
+	remaining := make([]db.GetScrobblesForReleaseYearLookupRow, 0, len(scrobbles))
+	for _, scrobble := range scrobbles {
+		if !resolved[scrobble.ID] {
+			remaining = append(remaining, scrobble)
+		}
+	}
+	return remaining
+}
+
+// findReleaseYearsForScrobbles resolves release years for a user's scrobbles, calling onProgress
+// periodically. Scrobbles are grouped by album MBID, track MBID, normalized artist + album and
+// normalized artist + track so each distinct key is looked up once, and keys already in the
+// shared lookup caches skip MusicBrainz. Each pass runs on a bounded pool of workers; a key's
+// result doesn't depend on the others, so the outcome matches a sequential run. queries must be
+// backed by a pool, the workers update scrobbles concurrently.
+func findReleaseYearsForScrobbles(ctx context.Context, queries *db.Queries, username string, year int, onProgress func(ReleaseYearStats)) (ReleaseYearStats, error) {
+	log.Printf("Starting release year lookup for user '%s', year %d", username, year)
+
+	config := releaseYearConfig
+	var counters releaseYearCounters
+	var stats ReleaseYearStats
+	var total int
+
+	// Workers report concurrently, onProgress sees one snapshot at a time
+	var progressMu sync.Mutex
+	reportProgress := func(phase string) {
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		stats = counters.snapshot(phase, total)
+		if onProgress != nil {
+			onProgress(stats)
+		}
+	}
+
+	// addProcessed counts updated scrobbles, reporting progress every 100
+	addProcessed := func(phase string, n int) {
+		processed := counters.processed.Add(int64(n))
+		if processed/100 != (processed-int64(n))/100 {
+			log.Printf("Progress: %d/%d scrobbles processed", processed, total)
+			reportProgress(phase)
+		}
+	}
+
+	cache := newReleaseYearCache(queries, config.NegativeCacheTTL, config.Rules())
+
+	// Get scrobbles that need release year lookup
+	scrobbles, err := queries.GetScrobblesForReleaseYearLookup(ctx, db.GetScrobblesForReleaseYearLookupParams{
+		Username: username,
+		Year:     int32(year),
+	})
+	if err != nil {
+		return stats, fmt.Errorf("failed to get scrobbles: %w", err)
+	}
+
+	log.Printf("Found %d scrobbles to process", len(scrobbles))
+	total = len(scrobbles)
+
+	// updateGroup writes a match to every scrobble in a group; a match without a year is recorded as not found
+	updateGroup := func(group releaseYearGroup, match ReleaseYearMatch) (int, error) {
+		params := db.UpdateScrobblesReleaseYearParams{
+			ReleaseYear:      match.Year,
+			ReleaseMonth:     match.Month,
+			ReleaseDay:       match.Day,
+			Method:           pgtype.Text{String: releaseYearMethodNotFound, Valid: true},
+			ReleaseGroupMbid: match.ReleaseGroupMbid,
+			RecordingMbid:    match.RecordingMbid,
+			Ids:              group.IDs,
+		}
+		if match.Year.Valid {
+			params.Method = pgtype.Text{String: match.Method, Valid: true}
+			params.Confidence = pgtype.Float4{Float32: match.Confidence, Valid: true}
+		}
+
+		updated, err := queries.UpdateScrobblesReleaseYear(ctx, params)
+		if err != nil {
+			log.Printf("Failed to update %d scrobbles for key %q: %v", len(group.IDs), group.Key, err)
+			return 0, err
+		}
+		return int(updated), nil
+	}
+
+	// resolveByMbid resolves each group from the cache or with lookup, caching definitive answers,
+	// and records the groups that got a year
+	resolved := make(map[pgtype.UUID]bool)
+	resolveByMbid := func(
+		groups []releaseYearGroup,
+		cached map[string]ReleaseYearMatch,
+		lookup func(context.Context, releaseYearGroup) (ReleaseYearMatch, error),
+		store func(context.Context, string, ReleaseYearMatch),
+	) {
+		found := make([]bool, len(groups))
+		runLookupPool(ctx, config.MbidConcurrency, len(groups), func(i int) {
+			group := groups[i]
+
+			match, ok := cached[group.Key]
+			if ok {
+				counters.cacheHits.Add(1)
+			} else {
+				lookupCtx, cancel := lookupContext(ctx, config.MbidTimeout)
+				defer cancel()
+
+				var err error
+				match, err = lookup(lookupCtx, group)
+				// Unknown MBIDs and missing years are worth remembering, timeouts and errors aren't
+				if ctx.Err() != nil {
+					return
+				}
+				if errors.Is(lookupCtx.Err(), context.DeadlineExceeded) {
+					log.Printf("MBID lookup for key %q timed out after %v", group.Key, config.MbidTimeout)
+					counters.timedOut.Add(int64(len(group.IDs)))
+					return
+				}
+				if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+					log.Printf("MBID lookup for key %q failed: %v", group.Key, err)
+					return
+				}
+				store(ctx, group.Key, match)
+			}
+
+			if !match.Year.Valid {
+				return
+			}
+
+			updated, err := updateGroup(group, match)
+			if err != nil {
+				return
+			}
+
+			found[i] = true
+			counters.mbidFound.Add(int64(updated))
+			if match.Redirected {
+				counters.redirected.Add(int64(updated))
+			}
+			addProcessed(releaseYearPhaseMbid, updated)
+		})
+
+		for i, group := range groups {
+			if found[i] {
+				for _, id := range group.IDs {
+					resolved[id] = true
+				}
+			}
+		}
+	}
+
+	// Pass 1: Process scrobbles with MBIDs (fast direct lookups), album MBIDs first, then track MBIDs
+	reportProgress(releaseYearPhaseMbid)
+	mbidStartTime := time.Now()
+
+	albumGroups := groupScrobbles(scrobbles, albumMbidKey)
+	log.Printf("Pass 1: Looking up %d distinct album MBIDs on %d workers...", len(albumGroups), config.MbidConcurrency)
+	resolveByMbid(albumGroups, cache.loadAlbums(ctx, albumGroups), func(ctx context.Context, group releaseYearGroup) (ReleaseYearMatch, error) {
+		return findReleaseYearByAlbumMbid(ctx, group.Scrobble.AlbumMbid.String)
+	}, cache.storeAlbum)
+	if ctx.Err() != nil {
+		reportProgress(releaseYearPhaseMbid)
+		return stats, fmt.Errorf("release year lookup stopped: %w", ctx.Err())
+	}
+
+	// If no album MBID or lookup failed, try track MBID
+	trackGroups := groupScrobbles(unresolvedScrobbles(scrobbles, resolved), trackMbidKey)
+	log.Printf("Pass 1: Looking up %d distinct track MBIDs on %d workers...", len(trackGroups), config.MbidConcurrency)
+	resolveByMbid(trackGroups, cache.loadRecordings(ctx, trackGroups), func(ctx context.Context, group releaseYearGroup) (ReleaseYearMatch, error) {
+		return findReleaseYearByTrackMbid(ctx, group.Scrobble.TrackMbid.String)
+	}, cache.storeRecording)
+	if ctx.Err() != nil {
+		reportProgress(releaseYearPhaseMbid)
+		return stats, fmt.Errorf("release year lookup stopped: %w", ctx.Err())
+	}
+	log.Printf("Pass 1 complete: %d found via MBID, %d of them through redirects, %d timed out (took %v)",
+		counters.mbidFound.Load(), counters.redirected.Load(), counters.timedOut.Load(), time.Since(mbidStartTime))
+
+	// Pass 2: Process remaining scrobbles with fuzzy search. Album titles rarely carry the
+	// "- 2011 Remaster" noise track titles do, so artist + album is tried before artist + track.
+	reportProgress(releaseYearPhaseFuzzy)
+	fuzzyStartTime := time.Now()
+
+	albumNameGroups := groupScrobbles(unresolvedScrobbles(scrobbles, resolved), albumNameKey)
+	log.Printf("Pass 2: Looking up %d distinct artist/album pairs with fuzzy search on %d workers...",
+		len(albumNameGroups), config.FuzzyConcurrency)
+	cachedAlbumNames := cache.loadArtistAlbums(ctx, albumNameGroups)
+	albumNameFound := make([]bool, len(albumNameGroups))
+	runLookupPool(ctx, config.FuzzyConcurrency, len(albumNameGroups), func(i int) {
+		group := albumNameGroups[i]
+
+		match, ok := cachedAlbumNames[group.Key]
+		if ok {
+			counters.cacheHits.Add(1)
+		} else {
+			lookupCtx, cancel := lookupContext(ctx, config.FuzzyTimeout)
+			defer cancel()
+
+			var err error
+			match, err = findReleaseYearByArtistAndAlbum(lookupCtx, group.Scrobble.ArtistName, group.Scrobble.ArtistMbid.String, group.Scrobble.AlbumName.String)
+
+			// Anything short of a definitive answer leaves the scrobbles to the track search
+			if ctx.Err() != nil {
+				return
+			}
+			if errors.Is(lookupCtx.Err(), context.DeadlineExceeded) {
+				log.Printf("Album search for '%s - %s' timed out after %v", group.Scrobble.ArtistName, group.Scrobble.AlbumName.String, config.FuzzyTimeout)
+				return
+			}
+			if err != nil && !errors.Is(err, errReleaseYearNotFound) {
+				log.Printf("Album search for '%s - %s' failed: %v", group.Scrobble.ArtistName, group.Scrobble.AlbumName.String, err)
+				return
+			}
+
+			cache.storeArtistAlbum(ctx, group.Key, match)
+		}
+
+		if !match.Year.Valid {
+			return
+		}
+
+		updated, err := updateGroup(group, match)
+		if err != nil {
+			return
+		}
+
+		albumNameFound[i] = true
+		counters.fuzzyFound.Add(int64(updated))
+		addProcessed(releaseYearPhaseFuzzy, updated)
+	})
+	if ctx.Err() != nil {
+		reportProgress(releaseYearPhaseFuzzy)
+		return stats, fmt.Errorf("release year lookup stopped: %w", ctx.Err())
+	}
+	for i, group := range albumNameGroups {
+		if albumNameFound[i] {
+			for _, id := range group.IDs {
+				resolved[id] = true
+			}
+		}
+	}
+
+	fuzzyGroups := groupScrobbles(unresolvedScrobbles(scrobbles, resolved), fuzzyKey)
+	log.Printf("Pass 2: Looking up %d distinct artist/track pairs with fuzzy search on %d workers...",
+		len(fuzzyGroups), config.FuzzyConcurrency)
+	cachedFuzzy := cache.loadArtistTracks(ctx, fuzzyGroups)
+	runLookupPool(ctx, config.FuzzyConcurrency, len(fuzzyGroups), func(i int) {
+		group := fuzzyGroups[i]
+
+		match, ok := cachedFuzzy[group.Key]
+		if ok {
+			counters.cacheHits.Add(1)
+		} else {
+			lookupCtx, cancel := lookupContext(ctx, config.FuzzyTimeout)
+			defer cancel()
+
+			var err error
+			match, err = findReleaseYearByArtistAndTrack(lookupCtx, group.Scrobble.ArtistName, group.Scrobble.ArtistMbid.String, group.Scrobble.TrackName)
+
+			// An interrupted or failed lookup must not mark the scrobbles as fetched without a year
+			if ctx.Err() != nil {
+				return
+			}
+			if errors.Is(lookupCtx.Err(), context.DeadlineExceeded) {
+				log.Printf("Fuzzy search for '%s - %s' timed out after %v", group.Scrobble.ArtistName, group.Scrobble.TrackName, config.FuzzyTimeout)
+				counters.timedOut.Add(int64(len(group.IDs)))
+				return
+			}
+			if err != nil && !errors.Is(err, errReleaseYearNotFound) {
+				log.Printf("Fuzzy search for '%s - %s' failed: %v", group.Scrobble.ArtistName, group.Scrobble.TrackName, err)
+				counters.errors.Add(int64(len(group.IDs)))
+				return
+			}
+
+			cache.storeArtistTrack(ctx, group.Key, match)
+		}
+
+		// Update scrobbles with release year (or NULL if not found)
+		updated, err := updateGroup(group, match)
+		if err != nil {
+			return
+		}
+
+		if match.Year.Valid {
+			counters.fuzzyFound.Add(int64(updated))
+		} else {
+			counters.notFound.Add(int64(updated))
+		}
+		addProcessed(releaseYearPhaseFuzzy, updated)
+	})
+	if ctx.Err() != nil {
+		reportProgress(releaseYearPhaseFuzzy)
+		return stats, fmt.Errorf("release year lookup stopped: %w", ctx.Err())
+	}
+	log.Printf("Pass 2 complete: %d found via fuzzy, %d not found, %d timed out, %d failed (took %v)",
+		counters.fuzzyFound.Load(), counters.notFound.Load(), counters.timedOut.Load(), counters.errors.Load(), time.Since(fuzzyStartTime))
+
+	reportProgress(releaseYearPhaseFuzzy)
+
+	log.Printf("Release year lookup complete: processed=%d, mbid_found=%d, mbid_redirected=%d, fuzzy_found=%d, not_found=%d, timed_out=%d, errors=%d, cache_hits=%d",
+		stats.Processed, stats.MbidFound, stats.Redirected, stats.FuzzyFound, stats.NotFound, stats.TimedOut, stats.Errors, stats.CacheHits)
+	return stats, nil
+}
+
+// runLookupPool calls lookup for every index in [0, n) on up to concurrency goroutines.
+// It stops handing out work once ctx is done and returns when all started lookups have finished.
+func runLookupPool(ctx context.Context, concurrency, n int, lookup func(i int)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				lookup(i)
+			}
+		}()
+	}
+
+feed:
+	for i := range n {
+		select {
+		case <-ctx.Done():
+			break feed
+		case indexes <- i:
+		}
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// lookupContext bounds a single lookup; a zero timeout leaves it unbounded
+func lookupContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"last-year-fm/worker/db"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestRunLookupPool(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+		n           int
+	}{
+		{name: "more items than workers", concurrency: 4, n: 250},
+		{name: "more workers than items", concurrency: 10, n: 3},
+		{name: "no items", concurrency: 4, n: 0},
+		{name: "zero concurrency runs sequentially", concurrency: 0, n: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			seen := make(map[int]int)
+			var active, maxActive atomic.Int32
+
+			runLookupPool(context.Background(), tt.concurrency, tt.n, func(i int) {
+				current := active.Add(1)
+				defer active.Add(-1)
+				for {
+					peak := maxActive.Load()
+					if current <= peak || maxActive.CompareAndSwap(peak, current) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+
+				mu.Lock()
+				seen[i]++
+				mu.Unlock()
+			})
+
+			if len(seen) != tt.n {
+				t.Errorf("visited %d indexes, want %d", len(seen), tt.n)
+			}
+			for i, count := range seen {
+				if count != 1 {
+					t.Errorf("index %d visited %d times", i, count)
+				}
+			}
+			if limit := int32(max(tt.concurrency, 1)); maxActive.Load() > limit {
+				t.Errorf("peak concurrency %d exceeds %d", maxActive.Load(), limit)
+			}
+		})
+	}
+}
+
+func TestRunLookupPoolStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+
+	runLookupPool(ctx, 2, 1000, func(i int) {
+		if calls.Add(1) == 10 {
+			cancel()
+		}
+	})
+
+	// Workers already holding an index finish it, nothing new is handed out
+	if got := calls.Load(); got >= 1000 || got < 10 {
+		t.Errorf("got %d lookups after cancelling at 10", got)
+	}
+}
+
+func TestGroupScrobbles(t *testing.T) {
+	scrobble := func(id byte, artist, track, albumMbid string) db.GetScrobblesForReleaseYearLookupRow {
+		return db.GetScrobblesForReleaseYearLookupRow{
+			ID:         pgtype.UUID{Bytes: [16]byte{id}, Valid: true},
+			ArtistName: artist,
+			TrackName:  track,
+			AlbumMbid:  pgtype.Text{String: albumMbid, Valid: albumMbid != ""},
+		}
+	}
+
+	scrobbles := []db.GetScrobblesForReleaseYearLookupRow{
+		scrobble(1, "Daft Punk", "One More Time", "AAAA"),
+		scrobble(2, "Radiohead", "Creep", ""),
+		scrobble(3, "daft punk", "One More Time (Radio Edit)", "aaaa"),
+		scrobble(4, "Daft Punk", "Digital Love", "bbbb"),
+		scrobble(5, "RADIOHEAD", "creep", ""),
+	}
+
+	tests := []struct {
+		name     string
+		key      func(db.GetScrobblesForReleaseYearLookupRow) string
+		expected [][]byte
+	}{
+		{
+			name:     "album MBID groups ignore case and skip scrobbles without one",
+			key:      albumMbidKey,
+			expected: [][]byte{{1, 3}, {4}},
+		},
+		{
+			name:     "fuzzy key folds case and version suffixes",
+			key:      fuzzyKey,
+			expected: [][]byte{{1, 3}, {2, 5}, {4}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := groupScrobbles(scrobbles, tt.key)
+			if len(groups) != len(tt.expected) {
+				t.Fatalf("got %d groups, want %d", len(groups), len(tt.expected))
+			}
+			for i, group := range groups {
+				if len(group.IDs) != len(tt.expected[i]) {
+					t.Fatalf("group %d has %d scrobbles, want %d", i, len(group.IDs), len(tt.expected[i]))
+				}
+				for j, id := range group.IDs {
+					if id.Bytes[0] != tt.expected[i][j] {
+						t.Errorf("group %d scrobble %d = %d, want %d", i, j, id.Bytes[0], tt.expected[i][j])
+					}
+				}
+				if group.Scrobble.ID != group.IDs[0] {
+					t.Errorf("group %d is looked up with scrobble %v, want the first one", i, group.Scrobble.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestFuzzyArtistKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		artist     string
+		artistMbid string
+		expected   string
+	}{
+		{
+			name:     "name without MBID is normalized",
+			artist:   "The Beatles",
+			expected: "beatles",
+		},
+		{
+			name:       "MBID replaces the name",
+			artist:     "Bush",
+			artistMbid: "A2A7D9A4-3B7E-4E5B-8F5D-D5E0A1C39F0E",
+			expected:   "mbid:a2a7d9a4-3b7e-4e5b-8f5d-d5e0a1c39f0e",
+		},
+		{
+			name:       "blank MBID falls back to the name",
+			artist:     "Yes",
+			artistMbid: " ",
+			expected:   "yes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scrobble := db.GetScrobblesForReleaseYearLookupRow{
+				ArtistName: tt.artist,
+				ArtistMbid: pgtype.Text{String: tt.artistMbid, Valid: tt.artistMbid != ""},
+			}
+			if result := fuzzyArtistKey(scrobble); result != tt.expected {
+				t.Errorf("fuzzyArtistKey(%q, %q) = %q, want %q", tt.artist, tt.artistMbid, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReleaseYearConfigRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        ReleaseYearConfig
+		b        ReleaseYearConfig
+		expected bool
+	}{
+		{
+			name:     "excluded type order doesn't matter",
+			a:        ReleaseYearConfig{PreferPrimaryTypes: true, ExcludedSecondaryTypes: []string{"live", "compilation"}},
+			b:        ReleaseYearConfig{PreferPrimaryTypes: true, ExcludedSecondaryTypes: []string{"compilation", "live"}},
+			expected: true,
+		},
+		{
+			name:     "excluded types change the rules",
+			a:        ReleaseYearConfig{PreferPrimaryTypes: true, ExcludedSecondaryTypes: []string{"compilation"}},
+			b:        ReleaseYearConfig{PreferPrimaryTypes: true, ExcludedSecondaryTypes: []string{"compilation", "live"}},
+			expected: false,
+		},
+		{
+			name:     "fuzzy threshold changes the rules",
+			a:        ReleaseYearConfig{PreferPrimaryTypes: true, FuzzyThreshold: 0.5},
+			b:        ReleaseYearConfig{PreferPrimaryTypes: true, FuzzyThreshold: 0.4},
+			expected: false,
+		},
+		{
+			name:     "alias locales change the rules",
+			a:        ReleaseYearConfig{AliasLocales: []string{"en"}},
+			b:        ReleaseYearConfig{AliasLocales: []string{"en", "ja"}},
+			expected: false,
+		},
+		{
+			name:     "primary type preference changes the rules",
+			a:        ReleaseYearConfig{PreferPrimaryTypes: true},
+			b:        ReleaseYearConfig{PreferPrimaryTypes: false},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.a.Rules() == tt.b.Rules()
+			if result != tt.expected {
+				t.Errorf("Rules() equal = %v, want %v (%q vs %q)", result, tt.expected, tt.a.Rules(), tt.b.Rules())
+			}
+		})
+	}
+
+	long := ReleaseYearConfig{ExcludedSecondaryTypes: []string{
+		"compilation", "soundtrack", "spokenword", "interview", "audiobook", "audio drama",
+		"live", "remix", "dj-mix", "mixtape/street", "demo", "field recording",
+	}}
+	if rules := long.Rules(); len(rules) > 128 {
+		t.Errorf("Rules() is %d characters, want at most 128", len(rules))
+	}
+}
@@ -0,0 +1,15 @@
+-- Per-transaction staging table for bulk scrobble imports. It is not part of the app schema:
+-- ingestScrobblesPage creates it with CreateScrobbleStagingTable and it is dropped on commit.
+-- This declaration only lets sqlc check the queries that read from it.
+CREATE TEMP TABLE scrobbles_staging (
+    username varchar(256) NOT NULL,
+    "trackName" varchar(512) NOT NULL,
+    "trackMbid" varchar(36),
+    "artistName" varchar(512) NOT NULL,
+    "artistMbid" varchar(36),
+    "albumName" varchar(512),
+    "albumMbid" varchar(36),
+    "scrobbledAt" timestamp with time zone NOT NULL,
+    "scrobbledAtUnix" varchar(32) NOT NULL,
+    year integer NOT NULL
+);
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// transliterate romanizes Cyrillic, Greek, Japanese kana and Hangul, leaving other characters
+// (including kanji, which can't be read without a dictionary) as they are. The output is a
+// lowercase search string, not a faithful romanization: it only has to get close enough for
+// trigram similarity to find the romanized name MusicBrainz knows.
+func transliterate(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		lower := unicode.ToLower(r)
+
+		if latin, ok := cyrillicToLatin[lower]; ok {
+			b.WriteString(latin)
+			continue
+		}
+		if latin, ok := greekToLatin[lower]; ok {
+			b.WriteString(latin)
+			continue
+		}
+		if isKana(r) {
+			n := romanizeKana(&b, runes[i:])
+			i += n - 1
+			continue
+		}
+		if r >= hangulFirst && r <= hangulLast {
+			b.WriteString(romanizeHangul(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// cyrillicToLatin covers Russian, Ukrainian, Belarusian and Serbian letters, roughly following BGN/PCGN
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u", 'ђ': "dj", 'ј': "j",
+	'љ': "lj", 'њ': "nj", 'ћ': "c", 'џ': "dz",
+}
+
+var greekToLatin = map[rune]string{
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th",
+	'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p",
+	'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps",
+	'ω': "o", 'ά': "a", 'έ': "e", 'ή': "i", 'ί': "i", 'ό': "o", 'ύ': "y", 'ώ': "o",
+	'ϊ': "i", 'ϋ': "y", 'ΐ': "i", 'ΰ': "y",
+}
+
+// hiraganaToLatin is Hepburn romanization; katakana is looked up by its hiragana counterpart
+var hiraganaToLatin = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o", 'ゔ': "vu", 'ゎ': "wa",
+}
+
+// Small kana that merge with the kana before them, e.g. き + ゃ is "kya" and ふ + ぁ is "fa"
+var (
+	smallYKana     = map[rune]string{'ゃ': "a", 'ゅ': "u", 'ょ': "o"}
+	smallVowelKana = map[rune]string{'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o"}
+)
+
+const (
+	smallTsu      = 'っ'
+	prolongedMark = 'ー'
+)
+
+func isKana(r rune) bool {
+	return (r >= 'ぁ' && r <= 'ゖ') || (r >= 'ァ' && r <= 'ヶ') || r == prolongedMark
+}
+
+// toHiragana maps katakana onto hiragana, which share their layout 0x60 code points apart
+func toHiragana(r rune) rune {
+	if r >= 'ァ' && r <= 'ヶ' {
+		return r - 0x60
+	}
+	return r
+}
+
+// romanizeKana writes the romanization of the kana at the start of runes and returns how many runes it used
+func romanizeKana(b *strings.Builder, runes []rune) int {
+	r := toHiragana(runes[0])
+
+	// The prolonged sound mark is dropped, as most informal romanizations do
+	if r == prolongedMark {
+		return 1
+	}
+
+	// A small tsu doubles the next consonant
+	if r == smallTsu {
+		if len(runes) > 1 && isKana(runes[1]) {
+			var next strings.Builder
+			used := romanizeKana(&next, runes[1:])
+			latin := next.String()
+			if strings.HasPrefix(latin, "ch") {
+				b.WriteString("t")
+			} else if latin != "" && !strings.ContainsRune("aeiou", rune(latin[0])) {
+				b.WriteByte(latin[0])
+			}
+			b.WriteString(latin)
+			return 1 + used
+		}
+		return 1
+	}
+
+	latin, ok := hiraganaToLatin[r]
+	if !ok {
+		b.WriteRune(runes[0])
+		return 1
+	}
+
+	if len(runes) > 1 {
+		next := toHiragana(runes[1])
+		if vowel, ok := smallYKana[next]; ok && len(latin) > 1 && strings.HasSuffix(latin, "i") {
+			// shi, chi and ji drop the y: sha, cha, ja
+			stem := latin[:len(latin)-1]
+			if latin == "shi" || latin == "chi" || latin == "ji" {
+				b.WriteString(stem + vowel)
+			} else {
+				b.WriteString(stem + "y" + vowel)
+			}
+			return 2
+		}
+		if vowel, ok := smallVowelKana[next]; ok && len(latin) > 0 && strings.ContainsRune("aeiou", rune(latin[len(latin)-1])) {
+			// ふぁ is "fa", てぃ is "ti", うぃ is "wi"
+			stem := latin[:len(latin)-1]
+			if stem == "" {
+				stem = "w"
+			}
+			b.WriteString(stem + vowel)
+			return 2
+		}
+	}
+
+	b.WriteString(latin)
+	return 1
+}
+
+// Hangul syllables are composed from an initial, a medial and an optional final jamo
+const (
+	hangulFirst = '가'
+	hangulLast  = '힣'
+)
+
+// Revised Romanization without the sound changes between syllables
+var (
+	hangulInitials = []string{"g", "kk", "n", "d", "tt", "r", "m", "b", "pp", "s", "ss", "", "j", "jj", "ch", "k", "t", "p", "h"}
+	hangulMedials  = []string{"a", "ae", "ya", "yae", "eo", "e", "yeo", "ye", "o", "wa", "wae", "oe", "yo", "u", "wo", "we", "wi", "yu", "eu", "ui", "i"}
+	hangulFinals   = []string{"", "k", "k", "k", "n", "n", "n", "t", "l", "k", "m", "l", "l", "l", "p", "l", "m", "p", "p", "t", "t", "ng", "t", "t", "k", "t", "p", "t"}
+)
+
+func romanizeHangul(r rune) string {
+	index := int(r - hangulFirst)
+	initial := index / (len(hangulMedials) * len(hangulFinals))
+	medial := index % (len(hangulMedials) * len(hangulFinals)) / len(hangulFinals)
+	final := index % len(hangulFinals)
+	return hangulInitials[initial] + hangulMedials[medial] + hangulFinals[final]
+}
@@ -0,0 +1,76 @@
+package main
+
+import "testing"
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "russian",
+			input:    "Кино",
+			expected: "kino",
+		},
+		{
+			name:     "russian with yo and soft sign",
+			input:    "Ёлка и Мельница",
+			expected: "yolka i melnitsa",
+		},
+		{
+			name:     "ukrainian",
+			input:    "Океан Ельзи",
+			expected: "okean elzi",
+		},
+		{
+			name:     "greek with accents",
+			input:    "Μίκης Θεοδωράκης",
+			expected: "mikis theodorakis",
+		},
+		{
+			name:     "katakana",
+			input:    "ヨルシカ",
+			expected: "yorushika",
+		},
+		{
+			name:     "small ya after chi and small tsu before t",
+			input:    "チャットモンチー",
+			expected: "chattomonchi",
+		},
+		{
+			name:     "small vowel kana",
+			input:    "ファンキー",
+			expected: "fanki",
+		},
+		{
+			name:     "hiragana with small yu and prolonged mark",
+			input:    "きゃりーぱみゅぱみゅ",
+			expected: "kyaripamyupamyu",
+		},
+		{
+			name:     "kanji is kept",
+			input:    "ずっと真夜中でいいのに",
+			expected: "zutto真夜中deiinoni",
+		},
+		{
+			name:     "hangul",
+			input:    "방탄소년단",
+			expected: "bangtansonyeondan",
+		},
+		{
+			name:     "latin is unchanged",
+			input:    "Daft Punk",
+			expected: "Daft Punk",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := transliterate(tt.input)
+			if result != tt.expected {
+				t.Errorf("transliterate(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Headers carrying the webhook signature, see signWebhookPayload
+const (
+	webhookTimestampHeader = "X-LastYearFM-Timestamp"
+	webhookSignatureHeader = "X-LastYearFM-Signature"
+)
+
+// WebhookConfig configures delivery of job events to the TS API
+type WebhookConfig struct {
+	URL            string
+	Secret         string
+	EveryPages     int
+	EveryScrobbles int
+	MaxAttempts    int
+	BaseBackoff    time.Duration
+	MaxBackoff     time.Duration
+	Timeout        time.Duration
+	QueueSize      int
+	DeadLetterPath string
+}
+
+// WebhookNotifier posts signed job events to a webhook, one at a time and in order.
+// Progress events are throttled to every EveryPages pages or EveryScrobbles scrobbles per job.
+type WebhookNotifier struct {
+	ctx        context.Context // Cancelled on shutdown, cutting retry backoff short
+	config     WebhookConfig
+	httpClient *http.Client
+	events     chan JobEvent
+	done       chan struct{}
+	deadMu     sync.Mutex
+
+	mu       sync.Mutex
+	lastSent map[string]int
+}
+
+// loadWebhookConfig reads the webhook settings, returning false when no webhook URL is configured.
+// A URL without JOB_WEBHOOK_SECRET is an error: the receiver couldn't tell our events from forged ones.
+func loadWebhookConfig() (WebhookConfig, bool, error) {
+	webhookURL := os.Getenv("JOB_WEBHOOK_URL")
+	if webhookURL == "" {
+		return WebhookConfig{}, false, nil
+	}
+
+	secret := os.Getenv("JOB_WEBHOOK_SECRET")
+	if secret == "" {
+		return WebhookConfig{}, false, fmt.Errorf("JOB_WEBHOOK_SECRET must be set when JOB_WEBHOOK_URL is")
+	}
+
+	return WebhookConfig{
+		URL:            webhookURL,
+		Secret:         secret,
+		EveryPages:     envInt("JOB_WEBHOOK_EVERY_PAGES", 5),
+		EveryScrobbles: envInt("JOB_WEBHOOK_EVERY_SCROBBLES", 500),
+		MaxAttempts:    envInt("JOB_WEBHOOK_MAX_ATTEMPTS", 5),
+		BaseBackoff:    envDuration("JOB_WEBHOOK_BASE_BACKOFF", 500*time.Millisecond),
+		MaxBackoff:     envDuration("JOB_WEBHOOK_MAX_BACKOFF", 10*time.Second),
+		Timeout:        envDuration("JOB_WEBHOOK_TIMEOUT", 5*time.Second),
+		QueueSize:      envInt("JOB_WEBHOOK_QUEUE_SIZE", 256),
+		DeadLetterPath: os.Getenv("JOB_WEBHOOK_DEAD_LETTER_PATH"),
+	}, true, nil
+}
+
+// NewWebhookNotifier starts delivering events. Once ctx is cancelled, a failed delivery is
+// dead-lettered instead of waiting to retry, so shutdown isn't held up by an unreachable webhook.
+func NewWebhookNotifier(ctx context.Context, config WebhookConfig) *WebhookNotifier {
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+	if config.QueueSize < 1 {
+		config.QueueSize = 1
+	}
+
+	n := &WebhookNotifier{
+		ctx:        ctx,
+		config:     config,
+		httpClient: &http.Client{Timeout: config.Timeout},
+		events:     make(chan JobEvent, config.QueueSize),
+		done:       make(chan struct{}),
+		lastSent:   make(map[string]int),
+	}
+	go n.run()
+	return n
+}
+
+// Notify queues an event for delivery. Events that don't fit in the queue are dead-lettered.
+func (n *WebhookNotifier) Notify(event JobEvent) {
+	if !n.shouldSend(event) {
+		return
+	}
+
+	select {
+	case n.events <- event:
+	default:
+		n.deadLetter(event, fmt.Errorf("delivery queue full"))
+	}
+}
+
+// shouldSend applies progress throttling and forgets jobs once they finish
+func (n *WebhookNotifier) shouldSend(event JobEvent) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	switch event.Type {
+	case jobEventProgress:
+		progress, every := event.Job.Processed, n.config.EveryScrobbles
+		if event.Job.Kind == jobKindImport {
+			progress, every = event.Job.PagesFetched, n.config.EveryPages
+		}
+		if progress-n.lastSent[event.Job.ID] < every {
+			return false
+		}
+		n.lastSent[event.Job.ID] = progress
+	case jobEventPhase:
+		// The genres phase counts its scrobbles from zero again
+		n.lastSent[event.Job.ID] = event.Job.Processed
+	case jobEventCompleted, jobEventFailed, jobEventCancelled:
+		delete(n.lastSent, event.Job.ID)
+	}
+
+	return true
+}
+
+// Close stops accepting events and waits for queued events to be delivered
+func (n *WebhookNotifier) Close() {
+	close(n.events)
+	<-n.done
+}
+
+func (n *WebhookNotifier) run() {
+	defer close(n.done)
+	for event := range n.events {
+		if err := n.deliver(event); err != nil {
+			n.deadLetter(event, err)
+		}
+	}
+}
+
+// deliver posts a single event, retrying network failures, 429s and 5xx responses with backoff
+func (n *WebhookNotifier) deliver(event JobEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= n.config.MaxAttempts; attempt++ {
+		retryable, err := n.post(body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		if !retryable || attempt == n.config.MaxAttempts {
+			break
+		}
+
+		delay := backoffDelay(attempt, n.config.BaseBackoff, n.config.MaxBackoff)
+		log.Printf("Webhook %s for job %s attempt %d/%d failed: %v (retrying in %v)",
+			event.Type, event.Job.ID, attempt, n.config.MaxAttempts, err, delay)
+		select {
+		case <-n.ctx.Done():
+			return fmt.Errorf("retry abandoned on shutdown: %w", lastErr)
+		case <-time.After(delay):
+		}
+	}
+
+	return lastErr
+}
+
+func (n *WebhookNotifier) post(body []byte) (bool, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, n.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookTimestampHeader, timestamp)
+	req.Header.Set(webhookSignatureHeader, signWebhookPayload(n.config.Secret, timestamp, body))
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		return true, fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+}
+
+// deadLetter records an undeliverable event in the log and, if configured, a JSON lines file
+func (n *WebhookNotifier) deadLetter(event JobEvent, reason error) {
+	log.Printf("Webhook dead-letter: %s for job %s: %v", event.Type, event.Job.ID, reason)
+
+	if n.config.DeadLetterPath == "" {
+		return
+	}
+
+	line, err := json.Marshal(struct {
+		JobEvent
+		Reason string `json:"reason"`
+	}{event, reason.Error()})
+	if err != nil {
+		log.Printf("Failed to encode dead-letter event: %v", err)
+		return
+	}
+
+	n.deadMu.Lock()
+	defer n.deadMu.Unlock()
+
+	f, err := os.OpenFile(n.config.DeadLetterPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		log.Printf("Failed to open dead-letter file: %v", err)
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		log.Printf("Failed to write dead-letter event: %v", err)
+	}
+}
+
+// signWebhookPayload returns the hex HMAC-SHA256 of "<timestamp>.<body>", prefixed with "sha256="
+func signWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
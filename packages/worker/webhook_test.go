@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      string
+		expected  string
+	}{
+		{
+			name:      "signs timestamp and body",
+			secret:    "secret",
+			timestamp: "1700000000",
+			body:      `{"a":1}`,
+			expected:  "sha256=49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := signWebhookPayload(tt.secret, tt.timestamp, []byte(tt.body))
+			if result != tt.expected {
+				t.Errorf("signWebhookPayload(%q, %q, %q) = %q, want %q", tt.secret, tt.timestamp, tt.body, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifierThrottlesProgress(t *testing.T) {
+	n := &WebhookNotifier{
+		config:   WebhookConfig{EveryPages: 5, EveryScrobbles: 100},
+		lastSent: make(map[string]int),
+	}
+
+	tests := []struct {
+		name     string
+		event    JobEvent
+		expected bool
+	}{
+		{
+			name:     "started is always sent",
+			event:    JobEvent{Type: jobEventStarted, Job: JobView{ID: "a", Kind: jobKindImport}},
+			expected: true,
+		},
+		{
+			name:     "import progress below threshold is dropped",
+			event:    JobEvent{Type: jobEventProgress, Job: JobView{ID: "a", Kind: jobKindImport, PagesFetched: 4}},
+			expected: false,
+		},
+		{
+			name:     "import progress at threshold is sent",
+			event:    JobEvent{Type: jobEventProgress, Job: JobView{ID: "a", Kind: jobKindImport, PagesFetched: 5}},
+			expected: true,
+		},
+		{
+			name:     "threshold counts from last sent progress",
+			event:    JobEvent{Type: jobEventProgress, Job: JobView{ID: "a", Kind: jobKindImport, PagesFetched: 9}},
+			expected: false,
+		},
+		{
+			name:     "release year progress uses scrobble threshold",
+			event:    JobEvent{Type: jobEventProgress, Job: JobView{ID: "b", Kind: jobKindFindReleaseYears, Processed: 100}},
+			expected: true,
+		},
+		{
+			name:     "phase is always sent",
+			event:    JobEvent{Type: jobEventPhase, Job: JobView{ID: "b", Kind: jobKindFindReleaseYears, Phase: genrePhase}},
+			expected: true,
+		},
+		{
+			name:     "genres progress counts from the phase change",
+			event:    JobEvent{Type: jobEventProgress, Job: JobView{ID: "b", Kind: jobKindFindReleaseYears, Phase: genrePhase, Processed: 100}},
+			expected: true,
+		},
+		{
+			name:     "completed is always sent",
+			event:    JobEvent{Type: jobEventCompleted, Job: JobView{ID: "a", Kind: jobKindImport}},
+			expected: true,
+		},
+		{
+			name:     "cancelled is always sent",
+			event:    JobEvent{Type: jobEventCancelled, Job: JobView{ID: "b", Kind: jobKindFindReleaseYears, Processed: 150}},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := n.shouldSend(tt.event)
+			if result != tt.expected {
+				t.Errorf("shouldSend(%s) = %v, want %v", tt.event.Type, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifierRetriesAndSigns(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		expected := signWebhookPayload("secret", r.Header.Get(webhookTimestampHeader), body)
+		if r.Header.Get(webhookSignatureHeader) != expected {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(context.Background(), WebhookConfig{
+		URL:         server.URL,
+		Secret:      "secret",
+		MaxAttempts: 3,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+		Timeout:     time.Second,
+		QueueSize:   1,
+	})
+	n.Notify(JobEvent{Type: jobEventStarted, Job: JobView{ID: "job"}})
+	n.Close()
+
+	if attempts.Load() != 2 {
+		t.Errorf("got %d attempts, want 2", attempts.Load())
+	}
+}
+
+func TestWebhookNotifierDeadLettersFailedEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	deadLetterPath := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+	n := NewWebhookNotifier(context.Background(), WebhookConfig{
+		URL:            server.URL,
+		MaxAttempts:    2,
+		BaseBackoff:    time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		Timeout:        time.Second,
+		QueueSize:      1,
+		DeadLetterPath: deadLetterPath,
+	})
+	n.Notify(JobEvent{Type: jobEventFailed, Job: JobView{ID: "job"}})
+	n.Close()
+
+	contents, err := os.ReadFile(deadLetterPath)
+	if err != nil {
+		t.Fatalf("failed to read dead-letter file: %v", err)
+	}
+
+	var entry struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal(contents, &entry); err != nil {
+		t.Fatalf("dead-letter entry is not JSON: %v", err)
+	}
+	if entry.Type != jobEventFailed || !strings.Contains(entry.Reason, "500") {
+		t.Errorf("unexpected dead-letter entry: %s", contents)
+	}
+}
+
+func TestWebhookNotifierDeadLettersOnShutdown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	deadLetterPath := filepath.Join(t.TempDir(), "dead-letter.jsonl")
+	n := NewWebhookNotifier(ctx, WebhookConfig{
+		URL:            server.URL,
+		Secret:         "secret",
+		MaxAttempts:    5,
+		BaseBackoff:    time.Hour,
+		MaxBackoff:     time.Hour,
+		Timeout:        time.Second,
+		QueueSize:      1,
+		DeadLetterPath: deadLetterPath,
+	})
+	n.Notify(JobEvent{Type: jobEventCompleted, Job: JobView{ID: "job"}})
+	cancel()
+
+	closed := make(chan struct{})
+	go func() {
+		n.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close waited out the retry backoff")
+	}
+
+	contents, err := os.ReadFile(deadLetterPath)
+	if err != nil {
+		t.Fatalf("failed to read dead-letter file: %v", err)
+	}
+	if !strings.Contains(string(contents), "retry abandoned on shutdown") {
+		t.Errorf("dead-letter entry %q doesn't record the abandoned retry", contents)
+	}
+}
+
+func TestLoadWebhookConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		secret        string
+		expectEnabled bool
+		expectErr     bool
+	}{
+		{
+			name: "no URL disables the webhook",
+		},
+		{
+			name:      "URL without a secret is rejected",
+			url:       "http://localhost:4321/internal/job-update",
+			expectErr: true,
+		},
+		{
+			name:          "URL and secret enable the webhook",
+			url:           "http://localhost:4321/internal/job-update",
+			secret:        "secret",
+			expectEnabled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JOB_WEBHOOK_URL", tt.url)
+			t.Setenv("JOB_WEBHOOK_SECRET", tt.secret)
+
+			config, enabled, err := loadWebhookConfig()
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("got error %v, want error=%v", err, tt.expectErr)
+			}
+			if enabled != tt.expectEnabled {
+				t.Errorf("got enabled=%v, want %v", enabled, tt.expectEnabled)
+			}
+			if enabled && config.Secret != tt.secret {
+				t.Errorf("got secret %q, want %q", config.Secret, tt.secret)
+			}
+		})
+	}
+}
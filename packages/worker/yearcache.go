@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"last-year-fm/worker/db"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// releaseYearCache reads and writes the shared MusicBrainz lookup caches in the app database.
+// Lookups are keyed like releaseYearGroup.Key; a cached match without a Year is a negative entry.
+// Cache failures are logged and treated as misses so enrichment never depends on the cache.
+type releaseYearCache struct {
+	queries       *db.Queries
+	negativeSince pgtype.Timestamptz
+	rules         pgtype.Text
+}
+
+// newReleaseYearCache ignores negative entries older than negativeTTL and entries resolved under other rules
+func newReleaseYearCache(queries *db.Queries, negativeTTL time.Duration, rules string) *releaseYearCache {
+	return &releaseYearCache{
+		queries:       queries,
+		negativeSince: pgtype.Timestamptz{Time: time.Now().Add(-negativeTTL), Valid: true},
+		rules:         pgtype.Text{String: rules, Valid: true},
+	}
+}
+
+func (c *releaseYearCache) loadAlbums(ctx context.Context, groups []releaseYearGroup) map[string]ReleaseYearMatch {
+	cached := make(map[string]ReleaseYearMatch)
+	if len(groups) == 0 {
+		return cached
+	}
+
+	rows, err := c.queries.GetAlbumYearCache(ctx, db.GetAlbumYearCacheParams{
+		AlbumMbids:    groupKeys(groups),
+		Rules:         c.rules,
+		NegativeSince: c.negativeSince,
+	})
+	if err != nil {
+		log.Printf("Failed to read album year cache: %v", err)
+		return cached
+	}
+
+	for _, row := range rows {
+		cached[row.AlbumMbid] = ReleaseYearMatch{
+			Year:             row.ReleaseYear,
+			Month:            row.ReleaseMonth,
+			Day:              row.ReleaseDay,
+			Method:           releaseYearMethodAlbumMbid,
+			ReleaseGroupMbid: row.ReleaseGroupMbid,
+			Confidence:       releaseYearConfidenceMbid,
+			Redirected:       row.Redirected,
+		}
+	}
+	return cached
+}
+
+func (c *releaseYearCache) loadRecordings(ctx context.Context, groups []releaseYearGroup) map[string]ReleaseYearMatch {
+	cached := make(map[string]ReleaseYearMatch)
+	if len(groups) == 0 {
+		return cached
+	}
+
+	rows, err := c.queries.GetRecordingYearCache(ctx, db.GetRecordingYearCacheParams{
+		TrackMbids:    groupKeys(groups),
+		Rules:         c.rules,
+		NegativeSince: c.negativeSince,
+	})
+	if err != nil {
+		log.Printf("Failed to read recording year cache: %v", err)
+		return cached
+	}
+
+	for _, row := range rows {
+		cached[row.TrackMbid] = ReleaseYearMatch{
+			Year:             row.ReleaseYear,
+			Month:            row.ReleaseMonth,
+			Day:              row.ReleaseDay,
+			Method:           releaseYearMethodTrackMbid,
+			ReleaseGroupMbid: row.ReleaseGroupMbid,
+			// A redirected key is the old MBID, not the recording that matched
+			RecordingMbid: pgtype.Text{String: row.TrackMbid, Valid: row.ReleaseGroupMbid.Valid && !row.Redirected},
+			Confidence:    releaseYearConfidenceMbid,
+			Redirected:    row.Redirected,
+		}
+	}
+	return cached
+}
+
+func (c *releaseYearCache) loadArtistTracks(ctx context.Context, groups []releaseYearGroup) map[string]ReleaseYearMatch {
+	cached := make(map[string]ReleaseYearMatch)
+	if len(groups) == 0 {
+		return cached
+	}
+
+	wanted := make(map[string]bool, len(groups))
+	artistKeys := make([]string, 0, len(groups))
+	trackKeys := make([]string, 0, len(groups))
+	for _, group := range groups {
+		wanted[group.Key] = true
+		artist, track := splitFuzzyKey(group.Key)
+		artistKeys = append(artistKeys, artist)
+		trackKeys = append(trackKeys, track)
+	}
+
+	rows, err := c.queries.GetArtistTrackYearCache(ctx, db.GetArtistTrackYearCacheParams{
+		ArtistKeys:    artistKeys,
+		TrackKeys:     trackKeys,
+		Rules:         c.rules,
+		NegativeSince: c.negativeSince,
+	})
+	if err != nil {
+		log.Printf("Failed to read artist/track year cache: %v", err)
+		return cached
+	}
+
+	for _, row := range rows {
+		key := joinFuzzyKey(row.ArtistKey, row.TrackKey)
+		if wanted[key] {
+			cached[key] = ReleaseYearMatch{
+				Year:             row.ReleaseYear,
+				Month:            row.ReleaseMonth,
+				Day:              row.ReleaseDay,
+				Method:           row.Method.String,
+				ReleaseGroupMbid: row.ReleaseGroupMbid,
+				RecordingMbid:    row.RecordingMbid,
+				Confidence:       row.Confidence.Float32,
+			}
+		}
+	}
+	return cached
+}
+
+func (c *releaseYearCache) loadArtistAlbums(ctx context.Context, groups []releaseYearGroup) map[string]ReleaseYearMatch {
+	cached := make(map[string]ReleaseYearMatch)
+	if len(groups) == 0 {
+		return cached
+	}
+
+	wanted := make(map[string]bool, len(groups))
+	artistKeys := make([]string, 0, len(groups))
+	albumKeys := make([]string, 0, len(groups))
+	for _, group := range groups {
+		wanted[group.Key] = true
+		artist, album := splitFuzzyKey(group.Key)
+		artistKeys = append(artistKeys, artist)
+		albumKeys = append(albumKeys, album)
+	}
+
+	rows, err := c.queries.GetArtistAlbumYearCache(ctx, db.GetArtistAlbumYearCacheParams{
+		ArtistKeys:    artistKeys,
+		AlbumKeys:     albumKeys,
+		Rules:         c.rules,
+		NegativeSince: c.negativeSince,
+	})
+	if err != nil {
+		log.Printf("Failed to read artist/album year cache: %v", err)
+		return cached
+	}
+
+	for _, row := range rows {
+		key := joinFuzzyKey(row.ArtistKey, row.AlbumKey)
+		if wanted[key] {
+			cached[key] = ReleaseYearMatch{
+				Year:             row.ReleaseYear,
+				Month:            row.ReleaseMonth,
+				Day:              row.ReleaseDay,
+				Method:           releaseYearMethodFuzzyAlbum,
+				ReleaseGroupMbid: row.ReleaseGroupMbid,
+				Confidence:       row.Confidence.Float32,
+			}
+		}
+	}
+	return cached
+}
+
+func (c *releaseYearCache) storeAlbum(ctx context.Context, key string, match ReleaseYearMatch) {
+	err := c.queries.UpsertAlbumYearCache(ctx, db.UpsertAlbumYearCacheParams{
+		AlbumMbid:        key,
+		ReleaseYear:      match.Year,
+		ReleaseMonth:     match.Month,
+		ReleaseDay:       match.Day,
+		ReleaseGroupMbid: match.ReleaseGroupMbid,
+		Redirected:       match.Redirected,
+		Rules:            c.rules,
+	})
+	if err != nil {
+		log.Printf("Failed to cache release year for album MBID %s: %v", key, err)
+	}
+}
+
+func (c *releaseYearCache) storeRecording(ctx context.Context, key string, match ReleaseYearMatch) {
+	err := c.queries.UpsertRecordingYearCache(ctx, db.UpsertRecordingYearCacheParams{
+		TrackMbid:        key,
+		ReleaseYear:      match.Year,
+		ReleaseMonth:     match.Month,
+		ReleaseDay:       match.Day,
+		ReleaseGroupMbid: match.ReleaseGroupMbid,
+		Redirected:       match.Redirected,
+		Rules:            c.rules,
+	})
+	if err != nil {
+		log.Printf("Failed to cache release year for track MBID %s: %v", key, err)
+	}
+}
+
+func (c *releaseYearCache) storeArtistTrack(ctx context.Context, key string, match ReleaseYearMatch) {
+	artist, track := splitFuzzyKey(key)
+	err := c.queries.UpsertArtistTrackYearCache(ctx, db.UpsertArtistTrackYearCacheParams{
+		ArtistKey:        artist,
+		TrackKey:         track,
+		ReleaseYear:      match.Year,
+		ReleaseMonth:     match.Month,
+		ReleaseDay:       match.Day,
+		Method:           pgtype.Text{String: match.Method, Valid: match.Method != ""},
+		ReleaseGroupMbid: match.ReleaseGroupMbid,
+		RecordingMbid:    match.RecordingMbid,
+		Confidence:       pgtype.Float4{Float32: match.Confidence, Valid: match.Year.Valid},
+		Rules:            c.rules,
+	})
+	if err != nil {
+		log.Printf("Failed to cache release year for '%s - %s': %v", artist, track, err)
+	}
+}
+
+func (c *releaseYearCache) storeArtistAlbum(ctx context.Context, key string, match ReleaseYearMatch) {
+	artist, album := splitFuzzyKey(key)
+	err := c.queries.UpsertArtistAlbumYearCache(ctx, db.UpsertArtistAlbumYearCacheParams{
+		ArtistKey:        artist,
+		AlbumKey:         album,
+		ReleaseYear:      match.Year,
+		ReleaseMonth:     match.Month,
+		ReleaseDay:       match.Day,
+		ReleaseGroupMbid: match.ReleaseGroupMbid,
+		Confidence:       pgtype.Float4{Float32: match.Confidence, Valid: match.Year.Valid},
+		Rules:            c.rules,
+	})
+	if err != nil {
+		log.Printf("Failed to cache release year for album '%s - %s': %v", artist, album, err)
+	}
+}
+
+func groupKeys(groups []releaseYearGroup) []string {
+	keys := make([]string, 0, len(groups))
+	for _, group := range groups {
+		keys = append(keys, group.Key)
+	}
+	return keys
+}
+
+// Fuzzy keys join the normalized artist and track (or album) with a NUL, which can't occur in either name
+func joinFuzzyKey(artist, track string) string {
+	return artist + "\x00" + track
+}
+
+func splitFuzzyKey(key string) (string, string) {
+	artist, track, _ := strings.Cut(key, "\x00")
+	return artist, track
+}